@@ -0,0 +1,171 @@
+package warrant
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// ObjectTyper can be implemented by domain types passed to ObjectFor to
+// declare their object type.
+type ObjectTyper interface {
+	WarrantObjectType() string
+}
+
+type taggedObject struct {
+	objectType string
+	objectId   string
+	relation   string
+}
+
+func (object taggedObject) GetObjectType() string {
+	return object.objectType
+}
+
+func (object taggedObject) GetObjectId() string {
+	return object.objectId
+}
+
+func (object taggedObject) GetRelation() string {
+	return object.relation
+}
+
+type taggedFields struct {
+	idIndex       []int
+	typeIndex     []int
+	relationIndex []int
+	staticType    string
+}
+
+var taggedFieldsCache sync.Map
+
+// ObjectFor adapts any struct into a WarrantObject, so that domain models can
+// be used directly as the object or subject of a check. Struct fields are
+// tagged with:
+//
+//	warrant:"id"            the object id
+//	warrant:"type"          a field holding the object type
+//	warrant:"type=document" a fixed object type, on any field
+//	warrant:"relation"      a subject relation, such as "member"
+//
+// A non-empty type field takes precedence over a fixed type, and the struct's
+// WarrantObjectType method is used when neither gives a type. Values
+// that already implement WarrantObject are returned as is.
+func ObjectFor(v interface{}) (WarrantObject, error) {
+	if object, ok := v.(WarrantObject); ok {
+		return object, nil
+	}
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil, Error{Message: "Cannot use a nil value as a warrant object"}
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil, Error{Message: fmt.Sprintf("Cannot use %T as a warrant object", v)}
+	}
+
+	fields, err := taggedFieldsOf(value.Type())
+	if err != nil {
+		return nil, err
+	}
+	object := taggedObject{
+		objectType: fields.staticType,
+		objectId:   fieldString(value, fields.idIndex),
+		relation:   fieldString(value, fields.relationIndex),
+	}
+	if objectType := fieldString(value, fields.typeIndex); objectType != "" {
+		object.objectType = objectType
+	}
+	if object.objectType == "" {
+		if typer, ok := objectTyperOf(value); ok {
+			object.objectType = typer.WarrantObjectType()
+		}
+	}
+	if object.objectType == "" {
+		return nil, Error{Message: fmt.Sprintf("No object type for %T: tag a field with warrant:\"type\" or implement WarrantObjectType", v)}
+	}
+	if object.objectId == "" {
+		return nil, Error{Message: fmt.Sprintf("Empty object id for %T", v)}
+	}
+	return object, nil
+}
+
+// MustObjectFor is like ObjectFor but panics if v cannot be adapted.
+func MustObjectFor(v interface{}) WarrantObject {
+	object, err := ObjectFor(v)
+	if err != nil {
+		panic(err)
+	}
+	return object
+}
+
+// objectTyperOf finds a WarrantObjectType method on the struct or, when it was
+// passed by pointer, on its pointer receiver.
+func objectTyperOf(value reflect.Value) (ObjectTyper, bool) {
+	if typer, ok := value.Interface().(ObjectTyper); ok {
+		return typer, true
+	}
+	if value.CanAddr() {
+		typer, ok := value.Addr().Interface().(ObjectTyper)
+		return typer, ok
+	}
+	return nil, false
+}
+
+func taggedFieldsOf(t reflect.Type) (*taggedFields, error) {
+	if cached, ok := taggedFieldsCache.Load(t); ok {
+		return cached.(*taggedFields), nil
+	}
+
+	fields := &taggedFields{}
+	for _, field := range reflect.VisibleFields(t) {
+		tag, ok := field.Tag.Lookup("warrant")
+		if !ok {
+			continue
+		}
+		for _, option := range strings.Split(tag, ",") {
+			name, value, hasValue := strings.Cut(strings.TrimSpace(option), "=")
+			switch {
+			case name == "id" && !hasValue:
+				if fields.idIndex != nil {
+					return nil, Error{Message: fmt.Sprintf("%s has more than one warrant:\"id\" field", t)}
+				}
+				fields.idIndex = field.Index
+			case name == "type" && hasValue:
+				fields.staticType = value
+			case name == "type":
+				fields.typeIndex = field.Index
+			case name == "relation" && !hasValue:
+				fields.relationIndex = field.Index
+			default:
+				return nil, Error{Message: fmt.Sprintf("Unknown warrant tag option %q on %s.%s", option, t, field.Name)}
+			}
+		}
+	}
+	if fields.idIndex == nil {
+		return nil, Error{Message: fmt.Sprintf("%s has no warrant:\"id\" field", t)}
+	}
+
+	taggedFieldsCache.Store(t, fields)
+	return fields, nil
+}
+
+func fieldString(value reflect.Value, index []int) string {
+	if index == nil {
+		return ""
+	}
+	field, err := value.FieldByIndexErr(index)
+	if err != nil {
+		return ""
+	}
+	for field.Kind() == reflect.Pointer || field.Kind() == reflect.Interface {
+		if field.IsNil() {
+			return ""
+		}
+		field = field.Elem()
+	}
+	return fmt.Sprint(field)
+}
@@ -0,0 +1,182 @@
+package warrant
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type taggedDocument struct {
+	Id    int    `warrant:"id,type=document"`
+	Title string `json:"title"`
+}
+
+type typedUser struct {
+	Id   string `warrant:"id"`
+	Kind string `warrant:"type,type=user"`
+}
+
+type methodTypedTenant struct {
+	Id *string `warrant:"id"`
+}
+
+func (methodTypedTenant) WarrantObjectType() string {
+	return "tenant"
+}
+
+type pointerTypedRole struct {
+	Id       string `warrant:"id"`
+	Relation string `warrant:"relation"`
+}
+
+func (*pointerTypedRole) WarrantObjectType() string {
+	return "role"
+}
+
+type embeddedId struct {
+	Id uint64 `warrant:"id"`
+}
+
+type embeddingFeature struct {
+	embeddedId
+	Kind string `warrant:"type"`
+}
+
+func TestObjectForIds(t *testing.T) {
+	assert := assert.New(t)
+
+	object, err := ObjectFor(taggedDocument{Id: 42})
+	if assert.NoError(err) {
+		assert.Equal("document", object.GetObjectType())
+		assert.Equal("42", object.GetObjectId())
+	}
+
+	tenantId := "acme"
+	object, err = ObjectFor(methodTypedTenant{Id: &tenantId})
+	if assert.NoError(err) {
+		assert.Equal("acme", object.GetObjectId())
+	}
+
+	object, err = ObjectFor(embeddingFeature{embeddedId: embeddedId{Id: 7}, Kind: "feature"})
+	if assert.NoError(err) {
+		assert.Equal("feature", object.GetObjectType())
+		assert.Equal("7", object.GetObjectId())
+	}
+
+	_, err = ObjectFor(methodTypedTenant{})
+	assert.ErrorContains(err, "Empty object id")
+}
+
+func TestObjectForTypePrecedence(t *testing.T) {
+	assert := assert.New(t)
+
+	object, err := ObjectFor(typedUser{Id: "1", Kind: "admin"})
+	if assert.NoError(err) {
+		assert.Equal("admin", object.GetObjectType())
+	}
+
+	object, err = ObjectFor(typedUser{Id: "1"})
+	if assert.NoError(err) {
+		assert.Equal("user", object.GetObjectType())
+	}
+
+	_, err = ObjectFor(methodTypedTenant{Id: new(string)})
+	assert.ErrorContains(err, "Empty object id")
+	tenantId := "acme"
+	object, err = ObjectFor(methodTypedTenant{Id: &tenantId})
+	if assert.NoError(err) {
+		assert.Equal("tenant", object.GetObjectType())
+	}
+
+	_, err = ObjectFor(embeddingFeature{embeddedId: embeddedId{Id: 7}})
+	assert.ErrorContains(err, "No object type")
+}
+
+func TestObjectForPointers(t *testing.T) {
+	assert := assert.New(t)
+
+	object, err := ObjectFor(&pointerTypedRole{Id: "admin", Relation: "member"})
+	if assert.NoError(err) {
+		assert.Equal("role", object.GetObjectType())
+		assert.Equal("admin", object.GetObjectId())
+		assert.Equal("member", object.(SubjectWithRelation).GetRelation())
+	}
+
+	role := &pointerTypedRole{Id: "admin"}
+	_, err = ObjectFor(&role)
+	assert.NoError(err)
+
+	// The method has a pointer receiver, so a value has no type.
+	_, err = ObjectFor(pointerTypedRole{Id: "admin"})
+	assert.ErrorContains(err, "No object type")
+
+	var nilRole *pointerTypedRole
+	_, err = ObjectFor(nilRole)
+	assert.ErrorContains(err, "nil value")
+
+	_, err = ObjectFor("document:1")
+	assert.ErrorContains(err, "Cannot use string")
+
+	subject := Subject{ObjectType: "user", ObjectId: "1"}
+	object, err = ObjectFor(subject)
+	if assert.NoError(err) {
+		assert.Equal(subject, object)
+	}
+}
+
+func TestTaggedFieldsOfRejectsBadTags(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := ObjectFor(struct {
+		Id string `warrant:"id,primary"`
+	}{Id: "1"})
+	assert.ErrorContains(err, `Unknown warrant tag option "primary"`)
+
+	_, err = ObjectFor(struct {
+		Id string `warrant:"id=1"`
+	}{Id: "1"})
+	assert.ErrorContains(err, `Unknown warrant tag option "id=1"`)
+
+	_, err = ObjectFor(struct {
+		Id    string `warrant:"id"`
+		Other string `warrant:"id"`
+	}{Id: "1"})
+	assert.ErrorContains(err, "more than one")
+
+	_, err = ObjectFor(struct {
+		Title string `warrant:"type=document"`
+	}{})
+	assert.ErrorContains(err, "no warrant:\"id\" field")
+}
+
+func TestWarrantCheckMarshalJSON(t *testing.T) {
+	assert := assert.New(t)
+
+	check := WarrantCheck{
+		Object:   MustObjectFor(taggedDocument{Id: 42}),
+		Relation: "viewer",
+		Subject:  MustObjectFor(&pointerTypedRole{Id: "admin", Relation: "member"}),
+		Context:  PolicyContext{"ip": "10.0.0.1"},
+	}
+	data, err := json.Marshal(check)
+	assert.NoError(err)
+	assert.JSONEq(`{
+		"objectType": "document",
+		"objectId": "42",
+		"relation": "viewer",
+		"subject": {"objectType": "role", "objectId": "admin", "relation": "member"},
+		"context": {"ip": "10.0.0.1"}
+	}`, string(data))
+
+	check.Subject = MustObjectFor(&pointerTypedRole{Id: "admin"})
+	data, err = json.Marshal(check)
+	assert.NoError(err)
+	assert.JSONEq(`{
+		"objectType": "document",
+		"objectId": "42",
+		"relation": "viewer",
+		"subject": {"objectType": "role", "objectId": "admin"},
+		"context": {"ip": "10.0.0.1"}
+	}`, string(data))
+}
@@ -2,10 +2,12 @@ package warrant
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"time"
 )
 
 const (
@@ -30,25 +32,84 @@ func NewApiClient(config ClientConfig) *ApiClient {
 }
 
 func (client ApiClient) MakeRequest(method string, path string, payload interface{}, options *RequestOptions) (*http.Response, error) {
-	var request *http.Request
-	var err error
+	instrumentation := client.Config.Instrumentation
+	if instrumentation == nil {
+		return client.makeRetriedRequest(method, path, payload, options)
+	}
 
-	url := client.Config.ApiEndpoint + path
-	if payload != nil {
-		postBody, err := json.Marshal(payload)
-		if err != nil {
-			return nil, WrapError("Invalid request payload", err)
+	tags := instrumentationTags(OperationName(method, path, payload), path, payload)
+	ctx, span := instrumentation.StartSpan(options.context(), tags)
+	if ctx != nil {
+		spanOptions := RequestOptions{}
+		if options != nil {
+			spanOptions = *options
 		}
-		requestBody := bytes.NewBuffer(postBody)
-		request, err = http.NewRequest(method, url, requestBody)
+		spanOptions.RequestContext = ctx
+		options = &spanOptions
+	}
+	start := time.Now()
+	resp, err := client.makeRetriedRequest(method, path, payload, options)
+	latency := time.Since(start)
+	span.End(err)
+	tags.Outcome = outcomeOf(err)
+	instrumentation.RecordLatency(tags, latency)
+	if err != nil {
+		instrumentation.IncError(tags, err)
+	}
+	return resp, err
+}
+
+func (client ApiClient) makeGuardedRequest(method string, path string, payload interface{}, options *RequestOptions) (*http.Response, error) {
+	if rateLimiter := client.rateLimiter(method, path); rateLimiter != nil {
+		release, err := rateLimiter.Wait(options.context())
 		if err != nil {
-			return nil, WrapError("Unable to create request", err)
+			return nil, err
 		}
-	} else {
-		request, err = http.NewRequest(method, url, nil)
+		defer release()
+	}
+
+	circuitBreaker := client.circuitBreaker(endpointGroupFor(path))
+	if circuitBreaker == nil {
+		return client.makeRequest(method, path, payload, options)
+	}
+
+	done, err := circuitBreaker.allow()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.makeRequest(method, path, payload, options)
+	done(err)
+	return resp, err
+}
+
+func (client ApiClient) circuitBreaker(group EndpointGroup) *CircuitBreaker {
+	if group == EndpointGroupCheck {
+		return client.Config.CheckCircuitBreaker
+	}
+	return client.Config.ManagementCircuitBreaker
+}
+
+// Checks and other reads share the check rate limiter; all other requests
+// use the write rate limiter.
+func (client ApiClient) rateLimiter(method string, path string) *RateLimiter {
+	if isIdempotentRead(method, path) {
+		return client.Config.CheckRateLimiter
+	}
+	return client.Config.WriteRateLimiter
+}
+
+func (client ApiClient) makeRequest(method string, path string, payload interface{}, options *RequestOptions) (*http.Response, error) {
+	var requestBody io.Reader
+	if payload != nil {
+		postBody, err := json.Marshal(payload)
 		if err != nil {
-			return nil, WrapError("Unable to create request", err)
+			return nil, WrapError("Invalid request payload", err)
 		}
+		requestBody = bytes.NewReader(postBody)
+	}
+	request, err := http.NewRequestWithContext(options.context(), method, client.Config.ApiEndpoint+path, requestBody)
+	if err != nil {
+		return nil, WrapError("Unable to create request", err)
 	}
 
 	if client.Config.ApiKey != "" {
@@ -57,23 +118,91 @@ func (client ApiClient) MakeRequest(method string, path string, payload interfac
 	if options != nil && options.WarrantToken != "" {
 		request.Header.Add("Warrant-Token", options.WarrantToken)
 	}
+	if options != nil && options.IdempotencyKey != "" {
+		request.Header.Add("Idempotency-Key", options.IdempotencyKey)
+	}
 	request.Header.Add("User-Agent", fmt.Sprintf("warrant-go/%s", ClientVersion))
 
-	resp, err := client.HttpClient.Do(request)
+	invoke := chainInterceptors(client.Config.Interceptors, client.send)
+	call := &Call{
+		Operation: OperationName(method, path, payload),
+		Request:   request,
+		Options:   options,
+	}
+	response, err := invoke(call)
 	if err != nil {
-		return nil, WrapError("Error making request", err)
+		return nil, err
+	}
+	if response == nil {
+		return nil, Error{
+			Message: fmt.Sprintf("Interceptor returned no response or error for %s", call.Operation),
+		}
+	}
+	return response.httpResponse(), nil
+}
+
+func (client ApiClient) send(call *Call) (*Response, error) {
+	start := time.Now()
+	resp, err := client.transmit(call)
+	client.logCall(call, resp, err, time.Since(start))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, WrapError("Error reading response", err)
+	}
+	return &Response{Response: resp, Data: data}, nil
+}
+
+func (client ApiClient) transmit(call *Call) (*http.Response, error) {
+	newRequest := func(ctx context.Context) (*http.Request, error) {
+		return cloneRequest(ctx, call.Request)
+	}
+
+	var resp *http.Response
+	var err error
+	if client.Config.HedgingPolicy != nil && isIdempotentRead(call.Request.Method, call.Request.URL.Path) {
+		resp, err = client.doHedged(call.Request.Context(), client.Config.HedgingPolicy, newRequest)
+	} else {
+		resp, err = client.do(call.Request.Context(), newRequest)
+	}
+	if err != nil && call.Request.Context().Err() != nil {
+		return nil, callerContextError{err: err}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if rateLimiter := client.rateLimiter(call.Request.Method, call.Request.URL.Path); rateLimiter != nil {
+		rateLimiter.observe(resp)
 	}
+
 	respStatus := resp.StatusCode
 	if respStatus < 200 || respStatus >= 400 {
+		defer resp.Body.Close()
 		msg, err := io.ReadAll(resp.Body)
 		errMsg := ""
 		if err == nil {
 			errMsg = string(msg)
 		}
 		return nil, Error{
-			Message: fmt.Sprintf("HTTP %d %s", respStatus, errMsg),
+			Message:    fmt.Sprintf("HTTP %d %s", respStatus, errMsg),
+			StatusCode: respStatus,
 		}
 	}
 
 	return resp, nil
 }
+
+func (client ApiClient) do(ctx context.Context, newRequest func(ctx context.Context) (*http.Request, error)) (*http.Response, error) {
+	request, err := newRequest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.HttpClient.Do(request)
+	if err != nil {
+		return nil, WrapError("Error making request", err)
+	}
+	return resp, nil
+}
@@ -0,0 +1,342 @@
+package bulk
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/warrant-dev/warrant-go/v6"
+	"github.com/warrant-dev/warrant-go/v6/object"
+	"github.com/warrant-dev/warrant-go/v6/tenant"
+	"github.com/warrant-dev/warrant-go/v6/user"
+)
+
+const (
+	DefaultChunkSize   = 100
+	DefaultConcurrency = 4
+)
+
+type Options struct {
+	// The most items sent in a single batch request.
+	ChunkSize int
+	// The most batch requests in flight at once.
+	Concurrency int
+}
+
+// ItemResult is the outcome for a single input item. Result holds the created
+// record for creates and the Warrant-Token of the request that removed the
+// item for deletes.
+type ItemResult[T any, R any] struct {
+	Index  int
+	Item   T
+	Result R
+	Err    error
+}
+
+// Report holds a result per input item in input order. WarrantToken is the
+// newest token of the batch requests that succeeded, which batches may finish
+// in any order when sent concurrently (see newerWarrantToken).
+type Report[T any, R any] struct {
+	Results      []ItemResult[T, R]
+	Succeeded    int
+	Failed       int
+	WarrantToken string
+}
+
+func (report Report[T, R]) Failures() []ItemResult[T, R] {
+	failures := make([]ItemResult[T, R], 0)
+	for _, result := range report.Results {
+		if result.Err != nil {
+			failures = append(failures, result)
+		}
+	}
+	return failures
+}
+
+type PartialFailureError struct {
+	Failed int
+	Total  int
+}
+
+func (err PartialFailureError) Error() string {
+	return fmt.Sprintf("Warrant error: %d of %d bulk items failed", err.Failed, err.Total)
+}
+
+type Client struct {
+	apiClient *warrant.ApiClient
+}
+
+func NewClient(config warrant.ClientConfig) Client {
+	return Client{
+		apiClient: warrant.NewApiClient(config),
+	}
+}
+
+func (c Client) CreateWarrants(params []warrant.WarrantParams, options *Options) (Report[warrant.WarrantParams, warrant.Warrant], error) {
+	warrantClient := warrant.NewClient(c.apiClient.Config)
+	return run(params, options, createWith(warrantClient.BatchCreate, func(createdWarrant warrant.Warrant) string {
+		return createdWarrant.WarrantToken
+	}))
+}
+
+func CreateWarrants(params []warrant.WarrantParams, options *Options) (Report[warrant.WarrantParams, warrant.Warrant], error) {
+	return getClient().CreateWarrants(params, options)
+}
+
+func (c Client) DeleteWarrants(params []warrant.WarrantParams, options *Options) (Report[warrant.WarrantParams, string], error) {
+	warrantClient := warrant.NewClient(c.apiClient.Config)
+	return run(params, options, deleteWith(warrantClient.BatchDelete))
+}
+
+func DeleteWarrants(params []warrant.WarrantParams, options *Options) (Report[warrant.WarrantParams, string], error) {
+	return getClient().DeleteWarrants(params, options)
+}
+
+func (c Client) CreateObjects(params []warrant.ObjectParams, options *Options) (Report[warrant.ObjectParams, warrant.Object], error) {
+	objectClient := object.NewClient(c.apiClient.Config)
+	return run(params, options, createWith(objectClient.BatchCreate, func(createdObject warrant.Object) string {
+		return createdObject.WarrantToken
+	}))
+}
+
+func CreateObjects(params []warrant.ObjectParams, options *Options) (Report[warrant.ObjectParams, warrant.Object], error) {
+	return getClient().CreateObjects(params, options)
+}
+
+func (c Client) DeleteObjects(params []warrant.ObjectParams, options *Options) (Report[warrant.ObjectParams, string], error) {
+	objectClient := object.NewClient(c.apiClient.Config)
+	return run(params, options, deleteWith(objectClient.BatchDelete))
+}
+
+func DeleteObjects(params []warrant.ObjectParams, options *Options) (Report[warrant.ObjectParams, string], error) {
+	return getClient().DeleteObjects(params, options)
+}
+
+func (c Client) CreateUsers(params []warrant.UserParams, options *Options) (Report[warrant.UserParams, warrant.User], error) {
+	userClient := user.NewClient(c.apiClient.Config)
+	return run(params, options, createWith(userClient.BatchCreate, func(createdUser warrant.User) string {
+		return createdUser.WarrantToken
+	}))
+}
+
+func CreateUsers(params []warrant.UserParams, options *Options) (Report[warrant.UserParams, warrant.User], error) {
+	return getClient().CreateUsers(params, options)
+}
+
+func (c Client) DeleteUsers(params []warrant.UserParams, options *Options) (Report[warrant.UserParams, string], error) {
+	userClient := user.NewClient(c.apiClient.Config)
+	return run(params, options, deleteWith(userClient.BatchDelete))
+}
+
+func DeleteUsers(params []warrant.UserParams, options *Options) (Report[warrant.UserParams, string], error) {
+	return getClient().DeleteUsers(params, options)
+}
+
+func (c Client) CreateTenants(params []warrant.TenantParams, options *Options) (Report[warrant.TenantParams, warrant.Tenant], error) {
+	tenantClient := tenant.NewClient(c.apiClient.Config)
+	return run(params, options, createWith(tenantClient.BatchCreate, func(createdTenant warrant.Tenant) string {
+		return createdTenant.WarrantToken
+	}))
+}
+
+func CreateTenants(params []warrant.TenantParams, options *Options) (Report[warrant.TenantParams, warrant.Tenant], error) {
+	return getClient().CreateTenants(params, options)
+}
+
+func (c Client) DeleteTenants(params []warrant.TenantParams, options *Options) (Report[warrant.TenantParams, string], error) {
+	tenantClient := tenant.NewClient(c.apiClient.Config)
+	return run(params, options, deleteWith(tenantClient.BatchDelete))
+}
+
+func DeleteTenants(params []warrant.TenantParams, options *Options) (Report[warrant.TenantParams, string], error) {
+	return getClient().DeleteTenants(params, options)
+}
+
+// batchFunc sends one batch request and returns a result per item along with
+// the Warrant-Token of the request.
+type batchFunc[T any, R any] func(chunk []T) ([]R, string, error)
+
+// createWith reads the request's Warrant-Token from the created records, which
+// all carry the token of the response they came from.
+func createWith[T any, R any](batchCreate func([]T) ([]R, error), warrantTokenOf func(R) string) batchFunc[T, R] {
+	return func(chunk []T) ([]R, string, error) {
+		created, err := batchCreate(chunk)
+		if err != nil {
+			return nil, "", err
+		}
+		warrantToken := ""
+		if len(created) > 0 {
+			warrantToken = warrantTokenOf(created[0])
+		}
+		return created, warrantToken, nil
+	}
+}
+
+func deleteWith[T any](batchDelete func([]T) (string, error)) batchFunc[T, string] {
+	return func(chunk []T) ([]string, string, error) {
+		warrantToken, err := batchDelete(chunk)
+		if err != nil {
+			return nil, "", err
+		}
+		results := make([]string, len(chunk))
+		for i := range results {
+			results[i] = warrantToken
+		}
+		return results, warrantToken, nil
+	}
+}
+
+type runner[T any, R any] struct {
+	items  []T
+	send   batchFunc[T, R]
+	report Report[T, R]
+	mu     sync.Mutex
+}
+
+func run[T any, R any](items []T, options *Options, send batchFunc[T, R]) (Report[T, R], error) {
+	if options == nil {
+		options = &Options{}
+	}
+	chunkSize := options.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+	concurrency := options.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+
+	r := &runner[T, R]{
+		items: items,
+		send:  send,
+		report: Report[T, R]{
+			Results: make([]ItemResult[T, R], len(items)),
+		},
+	}
+	for i, item := range items {
+		r.report.Results[i] = ItemResult[T, R]{
+			Index: i,
+			Item:  item,
+		}
+	}
+
+	chunks := make(chan [2]int)
+	var wg sync.WaitGroup
+	for worker := 0; worker < concurrency; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for bounds := range chunks {
+				r.sendChunk(bounds[0], bounds[1])
+			}
+		}()
+	}
+	for start := 0; start < len(items); start += chunkSize {
+		end := start + chunkSize
+		if end > len(items) {
+			end = len(items)
+		}
+		chunks <- [2]int{start, end}
+	}
+	close(chunks)
+	wg.Wait()
+
+	if r.report.Failed > 0 {
+		return r.report, PartialFailureError{
+			Failed: r.report.Failed,
+			Total:  len(items),
+		}
+	}
+	return r.report, nil
+}
+
+// sendChunk sends items[start:end] as one batch. If the batch is rejected, it
+// is split in half and each half retried until the failing items are
+// isolated. Outages are not bisected since every smaller batch would fail too.
+func (r *runner[T, R]) sendChunk(start int, end int) {
+	results, warrantToken, err := r.send(r.items[start:end])
+	if err != nil && end-start > 1 && !warrant.IsUnavailable(err) {
+		mid := start + (end-start)/2
+		r.sendChunk(start, mid)
+		r.sendChunk(mid, end)
+		return
+	}
+
+	// A batch answered with the wrong number of records may have been
+	// applied, so its items are failed without bisecting and resending.
+	if err == nil && len(results) != end-start {
+		err = warrant.Error{
+			Message: fmt.Sprintf("Batch request returned %d results for %d items", len(results), end-start),
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := start; i < end; i++ {
+		if err != nil {
+			r.report.Results[i].Err = err
+			r.report.Failed++
+			continue
+		}
+		r.report.Results[i].Result = results[i-start]
+		r.report.Succeeded++
+	}
+	if err == nil {
+		r.report.WarrantToken = newerWarrantToken(r.report.WarrantToken, warrantToken)
+	}
+}
+
+// newerWarrantToken returns the newer of two Warrant-Tokens. The Warrant API
+// issues tokens that encode, in base64, the id of the change they follow
+// ("<id>;<version>;<timestamp>"), and a later change has a higher id. If
+// either token isn't in that form, b, the token received later, is returned
+// unless it is empty.
+func newerWarrantToken(a string, b string) string {
+	if a == "" || b == "" {
+		if b == "" {
+			return a
+		}
+		return b
+	}
+	aId, aOk := warrantTokenChangeId(a)
+	bId, bOk := warrantTokenChangeId(b)
+	if aOk && bOk && aId > bId {
+		return a
+	}
+	return b
+}
+
+func warrantTokenChangeId(warrantToken string) (int64, bool) {
+	decoded, err := base64.StdEncoding.DecodeString(warrantToken)
+	if err != nil {
+		return 0, false
+	}
+	changeId, _, ok := strings.Cut(string(decoded), ";")
+	if !ok {
+		return 0, false
+	}
+	id, err := strconv.ParseInt(changeId, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return id, true
+}
+
+func getClient() Client {
+	config := warrant.ClientConfig{
+		ApiKey:                  warrant.ApiKey,
+		ApiEndpoint:             warrant.ApiEndpoint,
+		AuthorizeEndpoint:       warrant.AuthorizeEndpoint,
+		SelfServiceDashEndpoint: warrant.SelfServiceDashEndpoint,
+		HttpClient:              warrant.HttpClient,
+	}
+
+	return Client{
+		&warrant.ApiClient{
+			HttpClient: warrant.HttpClient,
+			Config:     config,
+		},
+	}
+}
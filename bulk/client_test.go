@@ -0,0 +1,199 @@
+package bulk
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/warrant-dev/warrant-go/v6"
+)
+
+func testWarrantToken(changeId int) string {
+	return base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%d;1;1700000000000000", changeId)))
+}
+
+// warrantServer creates warrants in batches. Batches holding an item with
+// ObjectId "bad" are rejected, and those holding "outage" fail with a 503.
+type warrantServer struct {
+	mu      sync.Mutex
+	batches [][]warrant.WarrantParams
+	// Called with the change id of each accepted batch before it responds.
+	beforeResponse func(changeId int)
+	// Drop the last record of accepted batches.
+	dropRecord bool
+}
+
+func (server *warrantServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var batch []warrant.WarrantParams
+	if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	server.mu.Lock()
+	server.batches = append(server.batches, batch)
+	changeId := len(server.batches)
+	server.mu.Unlock()
+
+	for _, item := range batch {
+		switch item.ObjectId {
+		case "bad":
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"message":"Invalid warrant"}`))
+			return
+		case "outage":
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+	}
+	if server.beforeResponse != nil {
+		server.beforeResponse(changeId)
+	}
+	created := make([]warrant.Warrant, 0, len(batch))
+	for _, item := range batch {
+		created = append(created, warrant.Warrant{ObjectType: item.ObjectType, ObjectId: item.ObjectId, Relation: item.Relation, Subject: item.Subject})
+	}
+	if server.dropRecord {
+		created = created[:len(created)-1]
+	}
+	w.Header().Set("Warrant-Token", testWarrantToken(changeId))
+	json.NewEncoder(w).Encode(created)
+}
+
+func (server *warrantServer) batchSizes() []int {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	sizes := make([]int, 0, len(server.batches))
+	for _, batch := range server.batches {
+		sizes = append(sizes, len(batch))
+	}
+	return sizes
+}
+
+func newTestClient(t *testing.T, handler http.Handler) Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return NewClient(warrant.ClientConfig{ApiKey: "key", ApiEndpoint: server.URL})
+}
+
+func warrantsFor(objectIds ...string) []warrant.WarrantParams {
+	params := make([]warrant.WarrantParams, 0, len(objectIds))
+	for _, objectId := range objectIds {
+		params = append(params, warrant.WarrantParams{
+			ObjectType: "document",
+			ObjectId:   objectId,
+			Relation:   "viewer",
+			Subject:    warrant.Subject{ObjectType: "user", ObjectId: "1"},
+		})
+	}
+	return params
+}
+
+func TestCreateWarrantsChunks(t *testing.T) {
+	assert := assert.New(t)
+	server := &warrantServer{}
+	client := newTestClient(t, server)
+
+	report, err := client.CreateWarrants(warrantsFor("a", "b", "c", "d", "e"), &Options{ChunkSize: 2, Concurrency: 1})
+	assert.NoError(err)
+	assert.Equal([]int{2, 2, 1}, server.batchSizes())
+	assert.Equal(5, report.Succeeded)
+	assert.Zero(report.Failed)
+	assert.Equal(testWarrantToken(3), report.WarrantToken)
+	for i, objectId := range []string{"a", "b", "c", "d", "e"} {
+		assert.Equal(i, report.Results[i].Index)
+		assert.Equal(objectId, report.Results[i].Result.ObjectId)
+	}
+}
+
+func TestCreateWarrantsBisectsToTheBadItem(t *testing.T) {
+	assert := assert.New(t)
+	server := &warrantServer{}
+	client := newTestClient(t, server)
+
+	report, err := client.CreateWarrants(warrantsFor("a", "b", "bad", "d"), &Options{ChunkSize: 4, Concurrency: 1})
+	assert.Equal(PartialFailureError{Failed: 1, Total: 4}, err)
+	assert.Equal(3, report.Succeeded)
+	assert.Equal(1, report.Failed)
+	assert.Equal([]int{4, 2, 2, 1, 1}, server.batchSizes())
+	failures := report.Failures()
+	if assert.Len(failures, 1) {
+		assert.Equal(2, failures[0].Index)
+		assert.ErrorContains(failures[0].Err, "Invalid warrant")
+	}
+	assert.Equal("d", report.Results[3].Result.ObjectId)
+}
+
+func TestCreateWarrantsDoesNotBisectOutages(t *testing.T) {
+	assert := assert.New(t)
+	server := &warrantServer{}
+	client := newTestClient(t, server)
+
+	report, err := client.CreateWarrants(warrantsFor("a", "outage", "c", "d", "e"), &Options{ChunkSize: 4, Concurrency: 1})
+	assert.Equal(PartialFailureError{Failed: 4, Total: 5}, err)
+	assert.Equal([]int{4, 1}, server.batchSizes())
+	assert.Equal(1, report.Succeeded)
+	assert.True(warrant.IsUnavailable(report.Results[0].Err))
+	assert.NoError(report.Results[4].Err)
+}
+
+func TestCreateWarrantsFailsBatchesWithMissingRecords(t *testing.T) {
+	assert := assert.New(t)
+	server := &warrantServer{dropRecord: true}
+	client := newTestClient(t, server)
+
+	report, err := client.CreateWarrants(warrantsFor("a", "b", "c"), &Options{ChunkSize: 3})
+	assert.Equal(PartialFailureError{Failed: 3, Total: 3}, err)
+	assert.Equal([]int{3}, server.batchSizes())
+	assert.ErrorContains(report.Results[0].Err, "returned 2 results for 3 items")
+	assert.Empty(report.WarrantToken)
+}
+
+func TestCreateWarrantsKeepsOrderAndNewestTokenWithConcurrency(t *testing.T) {
+	assert := assert.New(t)
+	server := &warrantServer{
+		// Earlier changes respond last.
+		beforeResponse: func(changeId int) {
+			time.Sleep(time.Duration(10-changeId) * 5 * time.Millisecond)
+		},
+	}
+	client := newTestClient(t, server)
+
+	objectIds := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+	report, err := client.CreateWarrants(warrantsFor(objectIds...), &Options{ChunkSize: 1, Concurrency: 8})
+	assert.NoError(err)
+	for i, objectId := range objectIds {
+		assert.Equal(objectId, report.Results[i].Item.ObjectId)
+		assert.Equal(objectId, report.Results[i].Result.ObjectId)
+	}
+	assert.Equal(testWarrantToken(8), report.WarrantToken)
+}
+
+func TestDeleteWarrantsRecordsTokenPerItem(t *testing.T) {
+	assert := assert.New(t)
+	requests := 0
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Warrant-Token", testWarrantToken(requests))
+	}))
+
+	report, err := client.DeleteWarrants(warrantsFor("a", "b", "c"), &Options{ChunkSize: 2, Concurrency: 1})
+	assert.NoError(err)
+	assert.Equal([]string{testWarrantToken(1), testWarrantToken(1), testWarrantToken(2)}, []string{report.Results[0].Result, report.Results[1].Result, report.Results[2].Result})
+	assert.Equal(testWarrantToken(2), report.WarrantToken)
+}
+
+func TestNewerWarrantToken(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal(testWarrantToken(10), newerWarrantToken(testWarrantToken(10), testWarrantToken(9)))
+	assert.Equal(testWarrantToken(10), newerWarrantToken(testWarrantToken(9), testWarrantToken(10)))
+	assert.Equal(testWarrantToken(9), newerWarrantToken(testWarrantToken(9), ""))
+	assert.Equal(testWarrantToken(9), newerWarrantToken("", testWarrantToken(9)))
+	assert.Equal("opaque-2", newerWarrantToken("opaque-1", "opaque-2"))
+	assert.Equal("opaque", newerWarrantToken(testWarrantToken(9), "opaque"))
+}
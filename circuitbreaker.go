@@ -0,0 +1,243 @@
+package warrant
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrCircuitOpen = Error{
+	Message:    "Circuit breaker is open",
+	StatusCode: http.StatusServiceUnavailable,
+}
+
+type EndpointGroup string
+
+const (
+	EndpointGroupCheck      EndpointGroup = "check"
+	EndpointGroupManagement EndpointGroup = "management"
+)
+
+func endpointGroupFor(path string) EndpointGroup {
+	if i := strings.Index(path, "?"); i >= 0 {
+		path = path[:i]
+	}
+	if strings.HasSuffix(path, "/v2/check") {
+		return EndpointGroupCheck
+	}
+	return EndpointGroupManagement
+}
+
+type CircuitState int
+
+const (
+	CircuitClosed CircuitState = iota
+	CircuitHalfOpen
+	CircuitOpen
+)
+
+func (state CircuitState) String() string {
+	switch state {
+	case CircuitClosed:
+		return "closed"
+	case CircuitHalfOpen:
+		return "half-open"
+	case CircuitOpen:
+		return "open"
+	default:
+		return "unknown"
+	}
+}
+
+type CircuitBreakerSettings struct {
+	Name string
+	// The circuit opens after this many failures in a row. Zero disables the threshold.
+	ConsecutiveFailures int
+	// The circuit opens once at least MinRequests requests finished within
+	// Window and the share of failures among them reaches FailureRatio. Zero
+	// disables the threshold.
+	FailureRatio float64
+	MinRequests  int
+	Window       time.Duration
+	// How long the circuit stays open before a half-open probe is let through.
+	OpenTimeout       time.Duration
+	HalfOpenMaxProbes int
+	// Decides which errors count as failures. Defaults to IsUnavailable.
+	IsFailure     func(err error) bool
+	OnStateChange func(name string, from CircuitState, to CircuitState)
+}
+
+type CircuitBreaker struct {
+	settings CircuitBreakerSettings
+
+	mu                  sync.Mutex
+	state               CircuitState
+	generation          uint64
+	windowStart         time.Time
+	openedAt            time.Time
+	requests            int
+	failures            int
+	consecutiveFailures int
+	probes              int
+}
+
+type circuitTransition struct {
+	from CircuitState
+	to   CircuitState
+}
+
+func NewCircuitBreaker(settings CircuitBreakerSettings) *CircuitBreaker {
+	if settings.ConsecutiveFailures == 0 && settings.FailureRatio == 0 {
+		settings.ConsecutiveFailures = 5
+	}
+	if settings.MinRequests <= 0 {
+		settings.MinRequests = 10
+	}
+	if settings.Window <= 0 {
+		settings.Window = time.Minute
+	}
+	if settings.OpenTimeout <= 0 {
+		settings.OpenTimeout = 30 * time.Second
+	}
+	if settings.HalfOpenMaxProbes <= 0 {
+		settings.HalfOpenMaxProbes = 1
+	}
+	if settings.IsFailure == nil {
+		settings.IsFailure = IsUnavailable
+	}
+	return &CircuitBreaker{
+		settings:    settings,
+		state:       CircuitClosed,
+		windowStart: time.Now(),
+	}
+}
+
+func (cb *CircuitBreaker) State() CircuitState {
+	cb.mu.Lock()
+	state, transitions := cb.currentState(time.Now())
+	cb.mu.Unlock()
+	cb.notify(transitions)
+	return state
+}
+
+// allow reports whether a request may proceed. When it may, the returned
+// function must be called with the request's result. Requests are only
+// counted once they finish, so that requests still in flight don't dilute
+// the failure ratio.
+func (cb *CircuitBreaker) allow() (func(err error), error) {
+	cb.mu.Lock()
+	state, transitions := cb.currentState(time.Now())
+	if state == CircuitOpen || (state == CircuitHalfOpen && cb.probes >= cb.settings.HalfOpenMaxProbes) {
+		cb.mu.Unlock()
+		cb.notify(transitions)
+		return nil, ErrCircuitOpen
+	}
+	if state == CircuitHalfOpen {
+		cb.probes++
+	}
+	generation := cb.generation
+	cb.mu.Unlock()
+	cb.notify(transitions)
+
+	return func(err error) {
+		cb.done(generation, err)
+	}, nil
+}
+
+func (cb *CircuitBreaker) done(generation uint64, err error) {
+	cb.mu.Lock()
+	now := time.Now()
+	state, transitions := cb.currentState(now)
+	if generation == cb.generation {
+		if err != nil && cb.settings.IsFailure(err) {
+			transitions = append(transitions, cb.onFailure(state, now)...)
+		} else {
+			transitions = append(transitions, cb.onSuccess(state, now)...)
+		}
+	}
+	cb.mu.Unlock()
+	cb.notify(transitions)
+}
+
+func (cb *CircuitBreaker) onSuccess(state CircuitState, now time.Time) []circuitTransition {
+	switch state {
+	case CircuitClosed:
+		cb.requests++
+		cb.consecutiveFailures = 0
+	case CircuitHalfOpen:
+		return cb.setState(CircuitClosed, now)
+	}
+	return nil
+}
+
+func (cb *CircuitBreaker) onFailure(state CircuitState, now time.Time) []circuitTransition {
+	switch state {
+	case CircuitClosed:
+		cb.requests++
+		cb.failures++
+		cb.consecutiveFailures++
+		if cb.shouldTrip() {
+			return cb.setState(CircuitOpen, now)
+		}
+	case CircuitHalfOpen:
+		return cb.setState(CircuitOpen, now)
+	}
+	return nil
+}
+
+func (cb *CircuitBreaker) shouldTrip() bool {
+	if cb.settings.ConsecutiveFailures > 0 && cb.consecutiveFailures >= cb.settings.ConsecutiveFailures {
+		return true
+	}
+	if cb.settings.FailureRatio > 0 && cb.requests >= cb.settings.MinRequests {
+		return float64(cb.failures)/float64(cb.requests) >= cb.settings.FailureRatio
+	}
+	return false
+}
+
+func (cb *CircuitBreaker) currentState(now time.Time) (CircuitState, []circuitTransition) {
+	switch cb.state {
+	case CircuitClosed:
+		if now.Sub(cb.windowStart) >= cb.settings.Window {
+			cb.resetCounts(now)
+		}
+	case CircuitOpen:
+		if now.Sub(cb.openedAt) >= cb.settings.OpenTimeout {
+			transitions := cb.setState(CircuitHalfOpen, now)
+			return cb.state, transitions
+		}
+	}
+	return cb.state, nil
+}
+
+func (cb *CircuitBreaker) setState(state CircuitState, now time.Time) []circuitTransition {
+	if cb.state == state {
+		return nil
+	}
+	previous := cb.state
+	cb.state = state
+	cb.generation++
+	cb.probes = 0
+	cb.consecutiveFailures = 0
+	cb.resetCounts(now)
+	if state == CircuitOpen {
+		cb.openedAt = now
+	}
+	return []circuitTransition{{from: previous, to: state}}
+}
+
+func (cb *CircuitBreaker) resetCounts(now time.Time) {
+	cb.windowStart = now
+	cb.requests = 0
+	cb.failures = 0
+}
+
+func (cb *CircuitBreaker) notify(transitions []circuitTransition) {
+	if cb.settings.OnStateChange == nil {
+		return
+	}
+	for _, transition := range transitions {
+		cb.settings.OnStateChange(cb.settings.Name, transition.from, transition.to)
+	}
+}
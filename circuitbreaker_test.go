@@ -0,0 +1,109 @@
+package warrant
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errUnavailable = Error{StatusCode: http.StatusServiceUnavailable}
+
+type recordedTransitions struct {
+	mu          sync.Mutex
+	transitions []string
+}
+
+func (recorded *recordedTransitions) onStateChange(name string, from CircuitState, to CircuitState) {
+	recorded.mu.Lock()
+	defer recorded.mu.Unlock()
+	recorded.transitions = append(recorded.transitions, from.String()+">"+to.String())
+}
+
+func callBreaker(t *testing.T, cb *CircuitBreaker, err error) {
+	done, allowErr := cb.allow()
+	if assert.NoError(t, allowErr) {
+		done(err)
+	}
+}
+
+func TestCircuitBreakerTransitions(t *testing.T) {
+	assert := assert.New(t)
+	recorded := &recordedTransitions{}
+	cb := NewCircuitBreaker(CircuitBreakerSettings{
+		Name:                "check",
+		ConsecutiveFailures: 3,
+		OpenTimeout:         20 * time.Millisecond,
+		OnStateChange:       recorded.onStateChange,
+	})
+
+	callBreaker(t, cb, errUnavailable)
+	callBreaker(t, cb, errUnavailable)
+	callBreaker(t, cb, nil)
+	callBreaker(t, cb, errUnavailable)
+	callBreaker(t, cb, errUnavailable)
+	assert.Equal(CircuitClosed, cb.State())
+
+	callBreaker(t, cb, errUnavailable)
+	assert.Equal(CircuitOpen, cb.State())
+	_, err := cb.allow()
+	assert.ErrorIs(err, ErrCircuitOpen)
+
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(CircuitHalfOpen, cb.State())
+	probeDone, err := cb.allow()
+	assert.NoError(err)
+	_, err = cb.allow()
+	assert.ErrorIs(err, ErrCircuitOpen)
+
+	probeDone(nil)
+	assert.Equal(CircuitClosed, cb.State())
+	assert.Equal([]string{"closed>open", "open>half-open", "half-open>closed"}, recorded.transitions)
+}
+
+func TestCircuitBreakerFailedProbeReopens(t *testing.T) {
+	assert := assert.New(t)
+	cb := NewCircuitBreaker(CircuitBreakerSettings{
+		ConsecutiveFailures: 1,
+		OpenTimeout:         20 * time.Millisecond,
+	})
+
+	callBreaker(t, cb, errUnavailable)
+	assert.Equal(CircuitOpen, cb.State())
+	time.Sleep(30 * time.Millisecond)
+	callBreaker(t, cb, errUnavailable)
+	assert.Equal(CircuitOpen, cb.State())
+}
+
+func TestCircuitBreakerIgnoresRejections(t *testing.T) {
+	cb := NewCircuitBreaker(CircuitBreakerSettings{
+		ConsecutiveFailures: 1,
+	})
+
+	callBreaker(t, cb, Error{StatusCode: http.StatusBadRequest})
+	assert.Equal(t, CircuitClosed, cb.State())
+}
+
+func TestCircuitBreakerFailureRatioCountsFinishedRequests(t *testing.T) {
+	assert := assert.New(t)
+	cb := NewCircuitBreaker(CircuitBreakerSettings{
+		FailureRatio: 0.5,
+		MinRequests:  4,
+	})
+
+	dones := make([]func(error), 0)
+	for i := 0; i < 10; i++ {
+		done, err := cb.allow()
+		assert.NoError(err)
+		dones = append(dones, done)
+	}
+	dones[0](errUnavailable)
+	dones[1](nil)
+	dones[2](nil)
+	assert.Equal(CircuitClosed, cb.State())
+
+	dones[3](errUnavailable)
+	assert.Equal(CircuitOpen, cb.State())
+}
@@ -1,30 +1,40 @@
 package warrant
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"net/http"
 	"net/url"
+	"time"
 
 	"github.com/google/go-querystring/query"
 )
 
 type WarrantClient struct {
-	apiClient *ApiClient
+	apiClient  *ApiClient
+	checkGroup *checkGroup
 }
 
 func NewClient(config ClientConfig) WarrantClient {
-	return WarrantClient{
-		apiClient: &ApiClient{
-			HttpClient: http.DefaultClient,
-			Config:     config,
-		},
+	client := WarrantClient{
+		apiClient: NewApiClient(config),
+	}
+	if config.CoalesceChecks {
+		client.checkGroup = newCheckGroup()
 	}
+	return client
 }
 
 func (c WarrantClient) Create(params *WarrantParams) (*Warrant, error) {
-	resp, err := c.apiClient.MakeRequest("POST", "/v2/warrants", params, &RequestOptions{})
+	if params == nil {
+		params = &WarrantParams{}
+	}
+	resp, err := c.apiClient.MakeRequest("POST", "/v2/warrants", params, &params.RequestOptions)
+	if errors.Is(err, ErrDuplicateOnRetry) {
+		return c.findWarrant(params)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -48,7 +58,18 @@ func Create(params *WarrantParams) (*Warrant, error) {
 }
 
 func (c WarrantClient) BatchCreate(params []WarrantParams) ([]Warrant, error) {
-	resp, err := c.apiClient.MakeRequest("POST", "/v2/warrants", params, &RequestOptions{})
+	resp, err := c.apiClient.MakeRequest("POST", "/v2/warrants", params, batchRequestOptions(params))
+	if errors.Is(err, ErrDuplicateOnRetry) {
+		existingWarrants := make([]Warrant, 0, len(params))
+		for i := range params {
+			existingWarrant, err := c.findWarrant(&params[i])
+			if err != nil {
+				return nil, err
+			}
+			existingWarrants = append(existingWarrants, *existingWarrant)
+		}
+		return existingWarrants, nil
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -74,7 +95,10 @@ func BatchCreate(params []WarrantParams) ([]Warrant, error) {
 }
 
 func (c WarrantClient) Delete(params *WarrantParams) (string, error) {
-	resp, err := c.apiClient.MakeRequest("DELETE", "/v2/warrants", params, &RequestOptions{})
+	if params == nil {
+		params = &WarrantParams{}
+	}
+	resp, err := c.apiClient.MakeRequest("DELETE", "/v2/warrants", params, &params.RequestOptions)
 	if err != nil {
 		return "", err
 	}
@@ -88,7 +112,7 @@ func Delete(params *WarrantParams) (string, error) {
 }
 
 func (c WarrantClient) BatchDelete(params []WarrantParams) (string, error) {
-	resp, err := c.apiClient.MakeRequest("DELETE", "/v2/warrants", params, &RequestOptions{})
+	resp, err := c.apiClient.MakeRequest("DELETE", "/v2/warrants", params, batchRequestOptions(params))
 	if err != nil {
 		return "", err
 	}
@@ -101,6 +125,77 @@ func BatchDelete(params []WarrantParams) (string, error) {
 	return getClient().BatchDelete(params)
 }
 
+// DeleteAll deletes warrants with one BatchDelete request per batchSize
+// warrants (100 if batchSize is not positive), in order. It returns how many
+// were deleted, which on error is the number deleted before the failing
+// batch, and the Warrant-Token of the last batch.
+func (c WarrantClient) DeleteAll(warrants []Warrant, batchSize int) (int, string, error) {
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+	deleted := 0
+	warrantToken := ""
+	for start := 0; start < len(warrants); start += batchSize {
+		end := start + batchSize
+		if end > len(warrants) {
+			end = len(warrants)
+		}
+		warrantsToDelete := make([]WarrantParams, 0, end-start)
+		for _, warrantToDelete := range warrants[start:end] {
+			warrantsToDelete = append(warrantsToDelete, WarrantParams{
+				ObjectType: warrantToDelete.ObjectType,
+				ObjectId:   warrantToDelete.ObjectId,
+				Relation:   warrantToDelete.Relation,
+				Subject:    warrantToDelete.Subject,
+				Policy:     warrantToDelete.Policy,
+			})
+		}
+		batchWarrantToken, err := c.BatchDelete(warrantsToDelete)
+		if err != nil {
+			return deleted, warrantToken, err
+		}
+		deleted += end - start
+		warrantToken = batchWarrantToken
+	}
+	return deleted, warrantToken, nil
+}
+
+func DeleteAll(warrants []Warrant, batchSize int) (int, string, error) {
+	return getClient().DeleteAll(warrants, batchSize)
+}
+
+// findWarrant fetches the warrant matching params. It is used when a retried
+// create finds that an earlier attempt already created the warrant.
+func (c WarrantClient) findWarrant(params *WarrantParams) (*Warrant, error) {
+	listParams := &ListWarrantParams{
+		ObjectType:      params.ObjectType,
+		ObjectId:        params.ObjectId,
+		Relation:        params.Relation,
+		SubjectType:     params.Subject.ObjectType,
+		SubjectId:       params.Subject.ObjectId,
+		SubjectRelation: params.Subject.Relation,
+	}
+	listParams.SetWarrantToken("latest")
+	listParams.SetContext(params.RequestContext)
+	existingWarrants, err := c.ListWarrants(listParams)
+	if err != nil {
+		return nil, err
+	}
+	for _, existingWarrant := range existingWarrants.Results {
+		if existingWarrant.Policy == params.Policy {
+			return &existingWarrant, nil
+		}
+	}
+	return nil, WrapError("Existing warrant not found", ErrDuplicateOnRetry)
+}
+
+func batchRequestOptions(params []WarrantParams) *RequestOptions {
+	if len(params) == 0 {
+		return &RequestOptions{}
+	}
+	return &params[0].RequestOptions
+}
+
 func (c WarrantClient) ListWarrants(listParams *ListWarrantParams) (ListResponse[Warrant], error) {
 	if listParams == nil {
 		listParams = &ListWarrantParams{}
@@ -131,6 +226,38 @@ func ListWarrants(listParams *ListWarrantParams) (ListResponse[Warrant], error)
 	return getClient().ListWarrants(listParams)
 }
 
+// ListAll pages through every warrant matching listParams. Unless a
+// Warrant-Token is set, it reads at the "latest" token, since the results
+// usually decide what to write next.
+func (c WarrantClient) ListAll(listParams *ListWarrantParams) ([]Warrant, error) {
+	pageParams := ListWarrantParams{}
+	if listParams != nil {
+		pageParams = *listParams
+	}
+	if pageParams.Limit <= 0 {
+		pageParams.Limit = 1000
+	}
+	if pageParams.WarrantToken == "" {
+		pageParams.SetWarrantToken("latest")
+	}
+	warrants := make([]Warrant, 0)
+	for {
+		listResponse, err := c.ListWarrants(&pageParams)
+		if err != nil {
+			return nil, err
+		}
+		warrants = append(warrants, listResponse.Results...)
+		if listResponse.NextCursor == "" {
+			return warrants, nil
+		}
+		pageParams.NextCursor = listResponse.NextCursor
+	}
+}
+
+func ListAll(listParams *ListWarrantParams) ([]Warrant, error) {
+	return getClient().ListAll(listParams)
+}
+
 func (c WarrantClient) Query(queryString string, params *QueryParams) (ListResponse[QueryResult], error) {
 	if params == nil {
 		params = &QueryParams{}
@@ -161,6 +288,37 @@ func Query(queryString string, params *QueryParams) (ListResponse[QueryResult],
 	return getClient().Query(queryString, params)
 }
 
+// QueryAll pages through every result of a query. Unless params say
+// otherwise, it reads 1000 results per page at the "latest" Warrant-Token.
+func (c WarrantClient) QueryAll(queryString string, params *QueryParams) ([]QueryResult, error) {
+	pageParams := QueryParams{}
+	if params != nil {
+		pageParams = *params
+	}
+	if pageParams.Limit <= 0 {
+		pageParams.Limit = 1000
+	}
+	if pageParams.WarrantToken == "" {
+		pageParams.SetWarrantToken("latest")
+	}
+	queryResults := make([]QueryResult, 0)
+	for {
+		queryResponse, err := c.Query(queryString, &pageParams)
+		if err != nil {
+			return nil, err
+		}
+		queryResults = append(queryResults, queryResponse.Results...)
+		if queryResponse.NextCursor == "" {
+			return queryResults, nil
+		}
+		pageParams.NextCursor = queryResponse.NextCursor
+	}
+}
+
+func QueryAll(queryString string, params *QueryParams) ([]QueryResult, error) {
+	return getClient().QueryAll(queryString, params)
+}
+
 func (c WarrantClient) Check(params *WarrantCheckParams) (bool, error) {
 	if params == nil {
 		params = &WarrantCheckParams{}
@@ -171,16 +329,7 @@ func (c WarrantClient) Check(params *WarrantCheckParams) (bool, error) {
 		Debug:          params.Debug,
 	}
 
-	checkResult, err := c.makeAuthorizeRequest(&accessCheckRequest)
-	if err != nil {
-		return false, err
-	}
-
-	if checkResult.Result == "Authorized" {
-		return true, nil
-	} else {
-		return false, nil
-	}
+	return c.check(&accessCheckRequest, params.FailureMode)
 }
 
 func Check(params *WarrantCheckParams) (bool, error) {
@@ -203,16 +352,7 @@ func (c WarrantClient) CheckMany(params *WarrantCheckManyParams) (bool, error) {
 		Debug:          params.Debug,
 	}
 
-	checkResult, err := c.makeAuthorizeRequest(&accessCheckRequest)
-	if err != nil {
-		return false, err
-	}
-
-	if checkResult.Result == "Authorized" {
-		return true, nil
-	} else {
-		return false, nil
-	}
+	return c.check(&accessCheckRequest, params.FailureMode)
 }
 
 func CheckMany(params *WarrantCheckManyParams) (bool, error) {
@@ -223,22 +363,7 @@ func (c WarrantClient) CheckUserHasPermission(params *PermissionCheckParams) (bo
 	if params == nil {
 		params = &PermissionCheckParams{}
 	}
-	return c.Check(&WarrantCheckParams{
-		RequestOptions: params.RequestOptions,
-		WarrantCheck: WarrantCheck{
-			Object: Object{
-				ObjectType: ObjectTypePermission,
-				ObjectId:   params.PermissionId,
-			},
-			Relation: "member",
-			Subject: Subject{
-				ObjectType: ObjectTypeUser,
-				ObjectId:   params.UserId,
-			},
-			Context: params.Context,
-		},
-		Debug: params.Debug,
-	})
+	return c.Check(params.warrantCheckParams())
 }
 
 func CheckUserHasPermission(params *PermissionCheckParams) (bool, error) {
@@ -257,13 +382,11 @@ func (c WarrantClient) CheckUserHasRole(params *RoleCheckParams) (bool, error) {
 				ObjectId:   params.RoleId,
 			},
 			Relation: "member",
-			Subject: Subject{
-				ObjectType: ObjectTypeUser,
-				ObjectId:   params.UserId,
-			},
-			Context: params.Context,
+			Subject:  checkSubject(params.Subject, params.UserId),
+			Context:  params.Context,
 		},
-		Debug: params.Debug,
+		Debug:       params.Debug,
+		FailureMode: params.FailureMode,
 	})
 }
 
@@ -286,7 +409,8 @@ func (c WarrantClient) CheckHasFeature(params *FeatureCheckParams) (bool, error)
 			Subject:  params.Subject,
 			Context:  params.Context,
 		},
-		Debug: params.Debug,
+		Debug:       params.Debug,
+		FailureMode: params.FailureMode,
 	})
 }
 
@@ -294,6 +418,95 @@ func CheckHasFeature(params *FeatureCheckParams) (bool, error) {
 	return getClient().CheckHasFeature(params)
 }
 
+type checkOutcome struct {
+	isAuthorized bool
+	err          error
+	failureMode  FailureMode
+}
+
+func (c WarrantClient) check(accessCheckRequest *AccessCheckRequest, failureMode FailureMode) (bool, error) {
+	outcome := c.evaluate(accessCheckRequest, failureMode)
+	if outcome.failureMode != "" {
+		return outcome.isAuthorized, nil
+	}
+	return outcome.isAuthorized, outcome.err
+}
+
+// evaluate runs a check and, when the Warrant API is unavailable, answers it
+// according to the failure mode. The original error is kept on the outcome
+// either way so callers such as the middleware can still report it.
+func (c WarrantClient) evaluate(accessCheckRequest *AccessCheckRequest, failureMode FailureMode) checkOutcome {
+	start := time.Now()
+	var outcome checkOutcome
+	var warrantToken string
+	checkResult, err := c.authorize(accessCheckRequest)
+	if err == nil {
+		warrantToken = checkResult.WarrantToken
+		outcome.isAuthorized = checkResult.Result == "Authorized"
+		c.storeStaleResult(accessCheckRequest, outcome.isAuthorized)
+	} else {
+		outcome.err = err
+		if IsUnavailable(err) {
+			c.applyFailureMode(accessCheckRequest, resolveFailureMode(failureMode, c.apiClient.Config.FailureMode), &outcome)
+		}
+	}
+
+	if c.apiClient.Config.DecisionSink != nil {
+		decision := newDecision(accessCheckRequest, outcome.isAuthorized, warrantToken, err, start)
+		decision.FailureMode = outcome.failureMode
+		c.apiClient.Config.DecisionSink.RecordDecision(decision)
+	}
+	return outcome
+}
+
+func (c WarrantClient) applyFailureMode(accessCheckRequest *AccessCheckRequest, failureMode FailureMode, outcome *checkOutcome) {
+	switch failureMode {
+	case FailureModeAllow:
+		outcome.isAuthorized = true
+		outcome.failureMode = FailureModeAllow
+	case FailureModeServeStale:
+		staleCache := c.apiClient.Config.StaleCheckCache
+		if staleCache == nil {
+			return
+		}
+		key, err := checkRequestKey(accessCheckRequest)
+		if err != nil {
+			return
+		}
+		if isAuthorized, ok := staleCache.load(key); ok {
+			outcome.isAuthorized = isAuthorized
+			outcome.failureMode = FailureModeServeStale
+		}
+	}
+}
+
+func (c WarrantClient) storeStaleResult(accessCheckRequest *AccessCheckRequest, isAuthorized bool) {
+	staleCache := c.apiClient.Config.StaleCheckCache
+	if staleCache == nil {
+		return
+	}
+	key, err := checkRequestKey(accessCheckRequest)
+	if err != nil {
+		return
+	}
+	staleCache.store(key, isAuthorized)
+}
+
+func (c WarrantClient) authorize(accessCheckRequest *AccessCheckRequest) (*WarrantCheckResult, error) {
+	if c.checkGroup == nil {
+		return c.makeAuthorizeRequest(accessCheckRequest)
+	}
+	key, ok := coalesceKey(accessCheckRequest)
+	if !ok {
+		return c.makeAuthorizeRequest(accessCheckRequest)
+	}
+	return c.checkGroup.do(accessCheckRequest.context(), key, func(ctx context.Context) (*WarrantCheckResult, error) {
+		sharedRequest := *accessCheckRequest
+		sharedRequest.SetContext(ctx)
+		return c.makeAuthorizeRequest(&sharedRequest)
+	})
+}
+
 func (c WarrantClient) makeAuthorizeRequest(params *AccessCheckRequest) (*WarrantCheckResult, error) {
 	resp, err := c.apiClient.MakeRequest("POST", "/v2/check", params, &params.RequestOptions)
 	if err != nil {
@@ -310,6 +523,9 @@ func (c WarrantClient) makeAuthorizeRequest(params *AccessCheckRequest) (*Warran
 	if err != nil {
 		return nil, WrapError("Invalid response from server", err)
 	}
+	if warrantToken := resp.Header.Get("Warrant-Token"); warrantToken != "" {
+		result.WarrantToken = warrantToken
+	}
 	return &result, nil
 }
 
@@ -323,7 +539,7 @@ func getClient() WarrantClient {
 	}
 
 	return WarrantClient{
-		&ApiClient{
+		apiClient: &ApiClient{
 			HttpClient: HttpClient,
 			Config:     config,
 		},
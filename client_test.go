@@ -0,0 +1,87 @@
+package warrant
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListAllPagesThroughWarrants(t *testing.T) {
+	assert := assert.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal("latest", r.Header.Get("Warrant-Token"))
+		assert.Equal("1000", r.URL.Query().Get("limit"))
+		assert.Equal("user", r.URL.Query().Get("subjectType"))
+		if r.URL.Query().Get("nextCursor") == "" {
+			w.Write([]byte(`{"results":[{"objectType":"role","objectId":"admin","relation":"member","subject":{"objectType":"user","objectId":"1"}}],"nextCursor":"page-2"}`))
+			return
+		}
+		w.Write([]byte(`{"results":[{"objectType":"tenant","objectId":"acme","relation":"member","subject":{"objectType":"user","objectId":"1"}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(ClientConfig{ApiKey: "key", ApiEndpoint: server.URL})
+	warrants, err := client.ListAll(&ListWarrantParams{SubjectType: "user", SubjectId: "1"})
+	assert.NoError(err)
+	if assert.Len(warrants, 2) {
+		assert.Equal("admin", warrants[0].ObjectId)
+		assert.Equal("acme", warrants[1].ObjectId)
+	}
+}
+
+func TestDeleteAllStopsAtFailingBatch(t *testing.T) {
+	assert := assert.New(t)
+	batches := make([][]WarrantParams, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var batch []WarrantParams
+		assert.NoError(json.NewDecoder(r.Body).Decode(&batch))
+		batches = append(batches, batch)
+		if len(batches) == 3 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Warrant-Token", "token-"+batch[0].ObjectId)
+	}))
+	defer server.Close()
+
+	warrants := make([]Warrant, 0)
+	for _, objectId := range []string{"a", "b", "c", "d", "e"} {
+		warrants = append(warrants, Warrant{ObjectType: "document", ObjectId: objectId, Relation: "viewer", Subject: Subject{ObjectType: "user", ObjectId: "1"}})
+	}
+	client := NewClient(ClientConfig{ApiKey: "key", ApiEndpoint: server.URL})
+	deleted, warrantToken, err := client.DeleteAll(warrants, 2)
+	assert.Error(err)
+	assert.Equal(4, deleted)
+	assert.Equal("token-c", warrantToken)
+	if assert.Len(batches, 3) {
+		assert.Len(batches[0], 2)
+		assert.Equal("e", batches[2][0].ObjectId)
+	}
+}
+
+func TestQueryAllPagesThroughResults(t *testing.T) {
+	assert := assert.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal("latest", r.Header.Get("Warrant-Token"))
+		assert.Equal("1000", r.URL.Query().Get("limit"))
+		assert.Equal("select permission where user:1 is *", r.URL.Query().Get("q"))
+		if r.URL.Query().Get("nextCursor") == "" {
+			w.Write([]byte(`{"results":[{"objectType":"permission","objectId":"view"}],"nextCursor":"page-2"}`))
+			return
+		}
+		w.Write([]byte(`{"results":[{"objectType":"permission","objectId":"edit","meta":{"name":"Edit"}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(ClientConfig{ApiKey: "key", ApiEndpoint: server.URL})
+	queryResults, err := client.QueryAll("select permission where user:1 is *", nil)
+	assert.NoError(err)
+	if assert.Len(queryResults, 2) {
+		assert.Equal("view", queryResults[0].ObjectId)
+		assert.Equal("edit", queryResults[1].ObjectId)
+		assert.Equal("Edit", queryResults[1].Meta["name"])
+	}
+}
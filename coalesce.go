@@ -0,0 +1,103 @@
+package warrant
+
+import (
+	"context"
+	"sync"
+)
+
+type checkCall struct {
+	done    chan struct{}
+	result  *WarrantCheckResult
+	err     error
+	cancel  context.CancelFunc
+	waiters int
+}
+
+// checkGroup lets concurrent identical checks share a single request to the
+// Warrant API. Only checks that are in flight at the same time are shared;
+// nothing is remembered once a request completes.
+type checkGroup struct {
+	mu    sync.Mutex
+	calls map[string]*checkCall
+}
+
+func newCheckGroup() *checkGroup {
+	return &checkGroup{
+		calls: make(map[string]*checkCall),
+	}
+}
+
+// do runs fn once per key in flight and hands its result to each caller.
+// The shared request runs on its own context, which is cancelled only once
+// every caller waiting on it has given up. Each caller still returns as soon
+// as its own ctx is done.
+func (group *checkGroup) do(ctx context.Context, key string, fn func(ctx context.Context) (*WarrantCheckResult, error)) (*WarrantCheckResult, error) {
+	group.mu.Lock()
+	call, ok := group.calls[key]
+	if ok {
+		call.waiters++
+	} else {
+		callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		call = &checkCall{
+			done:    make(chan struct{}),
+			cancel:  cancel,
+			waiters: 1,
+		}
+		group.calls[key] = call
+		go group.run(callCtx, key, call, fn)
+	}
+	group.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.result, call.err
+	case <-ctx.Done():
+		group.leave(key, call)
+		return nil, callerContextError{err: WrapError("Error making request", ctx.Err())}
+	}
+}
+
+func (group *checkGroup) run(ctx context.Context, key string, call *checkCall, fn func(ctx context.Context) (*WarrantCheckResult, error)) {
+	call.result, call.err = fn(ctx)
+	group.mu.Lock()
+	if group.calls[key] == call {
+		delete(group.calls, key)
+	}
+	group.mu.Unlock()
+	call.cancel()
+	close(call.done)
+}
+
+// leave removes a caller that gave up waiting. When it was the last one the
+// shared request is cancelled and forgotten so later checks start afresh.
+func (group *checkGroup) leave(key string, call *checkCall) {
+	group.mu.Lock()
+	defer group.mu.Unlock()
+	call.waiters--
+	if call.waiters > 0 {
+		return
+	}
+	if group.calls[key] == call {
+		delete(group.calls, key)
+	}
+	call.cancel()
+}
+
+// coalesceKey identifies checks that can share a request. The Warrant-Token is
+// part of the key so a check is never answered at a different consistency
+// level than it asked for. Checks using the "latest" token are not coalesced
+// at all because a request already in flight may have been sent before the
+// caller's own write.
+func coalesceKey(request *AccessCheckRequest) (string, bool) {
+	if request.WarrantToken == "latest" {
+		return "", false
+	}
+	key, err := checkRequestKey(request)
+	if err != nil {
+		return "", false
+	}
+	if request.Debug {
+		key = "debug:" + key
+	}
+	return request.WarrantToken + "|" + key, true
+}
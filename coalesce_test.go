@@ -0,0 +1,107 @@
+package warrant
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func waitForWaiters(group *checkGroup, key string, waiters int) {
+	for {
+		group.mu.Lock()
+		call, ok := group.calls[key]
+		joined := ok && call.waiters == waiters
+		group.mu.Unlock()
+		if joined {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestCheckGroupFollowerReturnsWhenItsContextIsDone(t *testing.T) {
+	assert := assert.New(t)
+	group := newCheckGroup()
+	release := make(chan struct{})
+	started := make(chan struct{})
+	leaderResult := make(chan *WarrantCheckResult)
+	go func() {
+		result, _ := group.do(context.Background(), "key", func(ctx context.Context) (*WarrantCheckResult, error) {
+			close(started)
+			<-release
+			return &WarrantCheckResult{Result: "Authorized"}, ctx.Err()
+		})
+		leaderResult <- result
+	}()
+	<-started
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := group.do(ctx, "key", func(ctx context.Context) (*WarrantCheckResult, error) {
+		t.Error("follower should share the leader's request")
+		return nil, nil
+	})
+	assert.ErrorIs(err, context.Canceled)
+	assert.False(IsUnavailable(err))
+
+	close(release)
+	assert.Equal("Authorized", (<-leaderResult).Result)
+}
+
+func TestCheckGroupSharedCallOutlivesLeaderContext(t *testing.T) {
+	assert := assert.New(t)
+	group := newCheckGroup()
+	release := make(chan struct{})
+	started := make(chan struct{})
+	leaderCtx, cancelLeader := context.WithCancel(context.Background())
+	leaderErr := make(chan error)
+	go func() {
+		_, err := group.do(leaderCtx, "key", func(ctx context.Context) (*WarrantCheckResult, error) {
+			close(started)
+			<-release
+			return &WarrantCheckResult{Result: "Authorized"}, ctx.Err()
+		})
+		leaderErr <- err
+	}()
+	<-started
+
+	followerResult := make(chan *WarrantCheckResult)
+	go func() {
+		result, err := group.do(context.Background(), "key", nil)
+		assert.NoError(err)
+		followerResult <- result
+	}()
+	waitForWaiters(group, "key", 2)
+
+	cancelLeader()
+	assert.ErrorIs(<-leaderErr, context.Canceled)
+	close(release)
+	assert.Equal("Authorized", (<-followerResult).Result)
+}
+
+func TestCheckGroupCancelsSharedCallWhenAllWaitersGiveUp(t *testing.T) {
+	assert := assert.New(t)
+	group := newCheckGroup()
+	var calls atomic.Int32
+	cancelled := make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+	fn := func(ctx context.Context) (*WarrantCheckResult, error) {
+		calls.Add(1)
+		<-ctx.Done()
+		close(cancelled)
+		return nil, ctx.Err()
+	}
+	go cancel()
+	_, err := group.do(ctx, "key", fn)
+	assert.ErrorIs(err, context.Canceled)
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("shared call was not cancelled")
+	}
+	assert.Equal(int32(1), calls.Load())
+}
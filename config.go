@@ -1,6 +1,9 @@
 package warrant
 
-import "net/http"
+import (
+	"log/slog"
+	"net/http"
+)
 
 var ApiKey string
 var ApiEndpoint string = "https://api.warrant.dev"
@@ -9,9 +12,23 @@ var SelfServiceDashEndpoint string = "https://self-serve.warrant.dev"
 var HttpClient *http.Client = http.DefaultClient
 
 type ClientConfig struct {
-	ApiKey                  string
-	ApiEndpoint             string
-	AuthorizeEndpoint       string
-	SelfServiceDashEndpoint string
-	HttpClient              *http.Client
+	ApiKey                   string
+	ApiEndpoint              string
+	AuthorizeEndpoint        string
+	SelfServiceDashEndpoint  string
+	HttpClient               *http.Client
+	DecisionSink             DecisionSink
+	FailureMode              FailureMode
+	StaleCheckCache          *StaleCheckCache
+	CheckCircuitBreaker      *CircuitBreaker
+	ManagementCircuitBreaker *CircuitBreaker
+	CoalesceChecks           bool
+	HedgingPolicy            *HedgingPolicy
+	Interceptors             []Interceptor
+	Instrumentation          Instrumentation
+	Logger                   *slog.Logger
+	CheckRateLimiter         *RateLimiter
+	WriteRateLimiter         *RateLimiter
+	RetryPolicy              *RetryPolicy
+	MetaSchemas              *MetaSchemaRegistry
 }
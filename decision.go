@@ -0,0 +1,245 @@
+package warrant
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type DecisionSink interface {
+	RecordDecision(decision Decision)
+}
+
+type DecisionCheck struct {
+	ObjectType string        `json:"objectType"`
+	ObjectId   string        `json:"objectId"`
+	Relation   string        `json:"relation"`
+	Subject    Subject       `json:"subject"`
+	Context    PolicyContext `json:"context,omitempty"`
+}
+
+type Decision struct {
+	Timestamp   time.Time       `json:"timestamp"`
+	Op          string          `json:"op,omitempty"`
+	Checks      []DecisionCheck `json:"checks"`
+	Authorized  bool            `json:"authorized"`
+	Error       string          `json:"error,omitempty"`
+	FailureMode FailureMode     `json:"failureMode,omitempty"`
+	Latency     time.Duration   `json:"latency"`
+	// The Warrant-Token returned with the result, identifying the snapshot
+	// that answered the check. Empty when the API didn't answer.
+	WarrantToken string            `json:"warrantToken,omitempty"`
+	Metadata     map[string]string `json:"metadata,omitempty"`
+}
+
+func newDecision(request *AccessCheckRequest, authorized bool, warrantToken string, err error, start time.Time) Decision {
+	checks := make([]DecisionCheck, 0)
+	for _, warrantCheck := range request.Warrants {
+		check := DecisionCheck{
+			Relation: warrantCheck.Relation,
+			Context:  warrantCheck.Context,
+		}
+		if warrantCheck.Object != nil {
+			check.ObjectType = warrantCheck.Object.GetObjectType()
+			check.ObjectId = warrantCheck.Object.GetObjectId()
+		}
+		if warrantCheck.Subject != nil {
+			check.Subject = Subject{
+				ObjectType: warrantCheck.Subject.GetObjectType(),
+				ObjectId:   warrantCheck.Subject.GetObjectId(),
+			}
+			if subject, ok := warrantCheck.Subject.(SubjectWithRelation); ok {
+				check.Subject.Relation = subject.GetRelation()
+			}
+		}
+		checks = append(checks, check)
+	}
+
+	decision := Decision{
+		Timestamp:    start,
+		Op:           request.Op,
+		Checks:       checks,
+		Authorized:   authorized,
+		Latency:      time.Since(start),
+		WarrantToken: warrantToken,
+		Metadata:     request.Metadata,
+	}
+	if err != nil {
+		decision.Error = err.Error()
+	}
+	return decision
+}
+
+type JSONLDecisionSink struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+	closer  io.Closer
+}
+
+func NewJSONLDecisionSink(w io.Writer) *JSONLDecisionSink {
+	sink := &JSONLDecisionSink{
+		encoder: json.NewEncoder(w),
+	}
+	if closer, ok := w.(io.Closer); ok {
+		sink.closer = closer
+	}
+	return sink
+}
+
+func NewJSONLFileDecisionSink(path string) (*JSONLDecisionSink, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, WrapError("Unable to open decision log", err)
+	}
+	return NewJSONLDecisionSink(file), nil
+}
+
+func (sink *JSONLDecisionSink) RecordDecision(decision Decision) {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	// Sinks have no way to report failures back to the check, so encoding errors are dropped.
+	_ = sink.encoder.Encode(decision)
+}
+
+func (sink *JSONLDecisionSink) Close() error {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	if sink.closer == nil {
+		return nil
+	}
+	return sink.closer.Close()
+}
+
+type SlogDecisionSink struct {
+	logger *slog.Logger
+	level  slog.Level
+}
+
+func NewSlogDecisionSink(logger *slog.Logger, level slog.Level) *SlogDecisionSink {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &SlogDecisionSink{
+		logger: logger,
+		level:  level,
+	}
+}
+
+func (sink *SlogDecisionSink) RecordDecision(decision Decision) {
+	attrs := []slog.Attr{
+		slog.Time("timestamp", decision.Timestamp),
+		slog.Bool("authorized", decision.Authorized),
+		slog.Duration("latency", decision.Latency),
+	}
+	if decision.Op != "" {
+		attrs = append(attrs, slog.String("op", decision.Op))
+	}
+	for i, check := range decision.Checks {
+		checkAttrs := []any{
+			slog.String("objectType", check.ObjectType),
+			slog.String("objectId", check.ObjectId),
+			slog.String("relation", check.Relation),
+			slog.String("subjectType", check.Subject.ObjectType),
+			slog.String("subjectId", check.Subject.ObjectId),
+		}
+		if check.Subject.Relation != "" {
+			checkAttrs = append(checkAttrs, slog.String("subjectRelation", check.Subject.Relation))
+		}
+		if len(check.Context) > 0 {
+			checkAttrs = append(checkAttrs, slog.Any("context", check.Context))
+		}
+		if len(decision.Checks) == 1 {
+			attrs = append(attrs, slog.Group("check", checkAttrs...))
+		} else {
+			attrs = append(attrs, slog.Group(fmt.Sprintf("check%d", i), checkAttrs...))
+		}
+	}
+	if decision.WarrantToken != "" {
+		attrs = append(attrs, slog.String("warrantToken", decision.WarrantToken))
+	}
+	if decision.Error != "" {
+		attrs = append(attrs, slog.String("error", decision.Error))
+	}
+	if len(decision.Metadata) > 0 {
+		metadataAttrs := make([]any, 0, len(decision.Metadata))
+		for key, value := range decision.Metadata {
+			metadataAttrs = append(metadataAttrs, slog.String(key, value))
+		}
+		attrs = append(attrs, slog.Group("metadata", metadataAttrs...))
+	}
+	sink.logger.LogAttrs(context.Background(), sink.level, "warrant authorization decision", attrs...)
+}
+
+type AsyncDecisionSink struct {
+	sink      DecisionSink
+	decisions chan Decision
+	done      chan struct{}
+	mu        sync.RWMutex
+	closed    bool
+	dropped   atomic.Uint64
+}
+
+func NewAsyncDecisionSink(sink DecisionSink, bufferSize int) *AsyncDecisionSink {
+	if bufferSize <= 0 {
+		bufferSize = 1024
+	}
+	asyncSink := &AsyncDecisionSink{
+		sink:      sink,
+		decisions: make(chan Decision, bufferSize),
+		done:      make(chan struct{}),
+	}
+	go asyncSink.run()
+	return asyncSink
+}
+
+func (sink *AsyncDecisionSink) run() {
+	defer close(sink.done)
+	for decision := range sink.decisions {
+		sink.sink.RecordDecision(decision)
+	}
+}
+
+// RecordDecision never blocks the caller. Decisions are dropped when the buffer is full.
+// Decisions recorded after Close are dropped too.
+func (sink *AsyncDecisionSink) RecordDecision(decision Decision) {
+	sink.mu.RLock()
+	defer sink.mu.RUnlock()
+	if sink.closed {
+		sink.dropped.Add(1)
+		return
+	}
+	select {
+	case sink.decisions <- decision:
+	default:
+		sink.dropped.Add(1)
+	}
+}
+
+func (sink *AsyncDecisionSink) Dropped() uint64 {
+	return sink.dropped.Load()
+}
+
+// Close stops accepting decisions, waits for buffered decisions to be delivered
+// and closes the wrapped sink if it is an io.Closer. Calling Close again is a
+// no-op.
+func (sink *AsyncDecisionSink) Close() error {
+	sink.mu.Lock()
+	if sink.closed {
+		sink.mu.Unlock()
+		return nil
+	}
+	sink.closed = true
+	close(sink.decisions)
+	sink.mu.Unlock()
+	<-sink.done
+	if closer, ok := sink.sink.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
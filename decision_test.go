@@ -0,0 +1,124 @@
+package warrant
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeDecisionSink struct {
+	mu        sync.Mutex
+	decisions []Decision
+}
+
+func (sink *fakeDecisionSink) RecordDecision(decision Decision) {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	sink.decisions = append(sink.decisions, decision)
+}
+
+func TestDecisionSinkRecordsResponseWarrantToken(t *testing.T) {
+	assert := assert.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Warrant-Token", "snapshot-42")
+		w.Write([]byte(`{"code":200,"result":"Authorized"}`))
+	}))
+	defer server.Close()
+
+	sink := &fakeDecisionSink{}
+	client := NewClient(ClientConfig{
+		ApiKey:            "key",
+		ApiEndpoint:       server.URL,
+		AuthorizeEndpoint: server.URL,
+		DecisionSink:      sink,
+	})
+	params := &WarrantCheckParams{
+		WarrantCheck: WarrantCheck{
+			Object:   Object{ObjectType: "document", ObjectId: "readme"},
+			Relation: "viewer",
+			Subject:  Userset("tenant", "acme", "member"),
+		},
+	}
+	params.SetWarrantToken("latest")
+	isAuthorized, err := client.Check(params)
+	assert.NoError(err)
+	assert.True(isAuthorized)
+
+	assert.Len(sink.decisions, 1)
+	decision := sink.decisions[0]
+	assert.True(decision.Authorized)
+	assert.Equal("snapshot-42", decision.WarrantToken)
+	assert.Empty(decision.Error)
+	assert.Equal([]DecisionCheck{{
+		ObjectType: "document",
+		ObjectId:   "readme",
+		Relation:   "viewer",
+		Subject:    Userset("tenant", "acme", "member"),
+	}}, decision.Checks)
+}
+
+func TestDecisionSinkRecordsErrors(t *testing.T) {
+	assert := assert.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"message":"Invalid relation"}`))
+	}))
+	defer server.Close()
+
+	sink := &fakeDecisionSink{}
+	client := NewClient(ClientConfig{
+		ApiKey:            "key",
+		ApiEndpoint:       server.URL,
+		AuthorizeEndpoint: server.URL,
+		DecisionSink:      sink,
+	})
+	isAuthorized, err := client.Check(&WarrantCheckParams{
+		WarrantCheck: WarrantCheck{
+			Object:   Object{ObjectType: "document", ObjectId: "readme"},
+			Relation: "unknown",
+			Subject:  Subject{ObjectType: "user", ObjectId: "1"},
+		},
+	})
+	assert.Error(err)
+	assert.False(isAuthorized)
+
+	assert.Len(sink.decisions, 1)
+	assert.False(sink.decisions[0].Authorized)
+	assert.Empty(sink.decisions[0].WarrantToken)
+	assert.Contains(sink.decisions[0].Error, "Invalid relation")
+}
+
+func TestAsyncDecisionSinkDropsDecisionsAfterClose(t *testing.T) {
+	assert := assert.New(t)
+	sink := &fakeDecisionSink{}
+	asyncSink := NewAsyncDecisionSink(sink, 10)
+	asyncSink.RecordDecision(Decision{Op: "first"})
+	assert.NoError(asyncSink.Close())
+	assert.NoError(asyncSink.Close())
+
+	asyncSink.RecordDecision(Decision{Op: "second"})
+	assert.Equal(uint64(1), asyncSink.Dropped())
+	assert.Len(sink.decisions, 1)
+	assert.Equal("first", sink.decisions[0].Op)
+}
+
+type closingDecisionSink struct {
+	fakeDecisionSink
+	closes int
+}
+
+func (sink *closingDecisionSink) Close() error {
+	sink.closes++
+	return nil
+}
+
+func TestAsyncDecisionSinkClosesWrappedSinkOnce(t *testing.T) {
+	sink := &closingDecisionSink{}
+	asyncSink := NewAsyncDecisionSink(sink, 10)
+	assert.NoError(t, asyncSink.Close())
+	assert.NoError(t, asyncSink.Close())
+	assert.Equal(t, 1, sink.closes)
+}
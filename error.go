@@ -1,9 +1,16 @@
 package warrant
 
-import "fmt"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
 
 type Error struct {
 	Message      string `json:"message"`
+	StatusCode   int    `json:"-"`
 	WrappedError error  `json:"-"`
 }
 
@@ -14,9 +21,50 @@ func (err Error) Error() string {
 	return fmt.Sprintf("Warrant error: %s", err.Message)
 }
 
+func (err Error) Unwrap() error {
+	return err.WrappedError
+}
+
 func WrapError(message string, err error) Error {
 	return Error{
 		Message:      message,
 		WrappedError: err,
 	}
 }
+
+// callerContextError marks a request that failed because the caller's own
+// context was cancelled or timed out, which says nothing about the Warrant API.
+type callerContextError struct {
+	err error
+}
+
+func (err callerContextError) Error() string {
+	return err.err.Error()
+}
+
+func (err callerContextError) Unwrap() error {
+	return err.err
+}
+
+// IsUnavailable reports whether err means the Warrant API could not answer,
+// as opposed to answering with a rejection of the request itself. Requests
+// cancelled by the caller, or that ran past the deadline of the caller's
+// context, are not outages.
+func IsUnavailable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var contextErr callerContextError
+	if errors.As(err, &contextErr) {
+		return false
+	}
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Op != "parse"
+	}
+	var warrantErr Error
+	if errors.As(err, &warrantErr) {
+		return warrantErr.StatusCode == http.StatusTooManyRequests || warrantErr.StatusCode >= http.StatusInternalServerError
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package warrant
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsUnavailable(t *testing.T) {
+	networkErr := WrapError("Error making request", &url.Error{Op: "Post", URL: "https://api.warrant.dev/v2/check", Err: errors.New("connection refused")})
+	tests := []struct {
+		name        string
+		err         error
+		unavailable bool
+	}{
+		{"nil", nil, false},
+		{"network error", networkErr, true},
+		{"rate limited", Error{StatusCode: http.StatusTooManyRequests}, true},
+		{"server error", Error{StatusCode: http.StatusServiceUnavailable}, true},
+		{"bad request", Error{StatusCode: http.StatusBadRequest}, false},
+		{"parse error", &url.Error{Op: "parse", Err: errors.New("invalid URL")}, false},
+		{"cancelled", WrapError("Error making request", &url.Error{Op: "Post", Err: context.Canceled}), false},
+		{"caller deadline", callerContextError{err: WrapError("Error making request", &url.Error{Op: "Post", Err: context.DeadlineExceeded})}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.unavailable, IsUnavailable(test.err))
+		})
+	}
+}
+
+func newSlowCheckServer(delay time.Duration) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+		}
+		w.Write([]byte(`{"code":200,"result":"Authorized"}`))
+	}))
+}
+
+func failOpenCheckParams(ctx context.Context) *WarrantCheckParams {
+	params := &WarrantCheckParams{
+		WarrantCheck: WarrantCheck{
+			Object:   Object{ObjectType: "document", ObjectId: "readme"},
+			Relation: "viewer",
+			Subject:  Subject{ObjectType: "user", ObjectId: "1"},
+		},
+		FailureMode: FailureModeAllow,
+	}
+	params.SetContext(ctx)
+	return params
+}
+
+func TestCancelledCheckIsNotAuthorizedByFailureMode(t *testing.T) {
+	assert := assert.New(t)
+	server := newSlowCheckServer(0)
+	defer server.Close()
+	client := NewClient(ClientConfig{ApiKey: "key", ApiEndpoint: server.URL, AuthorizeEndpoint: server.URL})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	isAuthorized, err := client.Check(failOpenCheckParams(ctx))
+	assert.False(isAuthorized)
+	assert.ErrorIs(err, context.Canceled)
+	assert.False(IsUnavailable(err))
+}
+
+func TestCallerDeadlineIsNotAuthorizedByFailureMode(t *testing.T) {
+	assert := assert.New(t)
+	server := newSlowCheckServer(300 * time.Millisecond)
+	defer server.Close()
+	client := NewClient(ClientConfig{ApiKey: "key", ApiEndpoint: server.URL, AuthorizeEndpoint: server.URL})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	isAuthorized, err := client.Check(failOpenCheckParams(ctx))
+	assert.False(isAuthorized)
+	assert.ErrorIs(err, context.DeadlineExceeded)
+	assert.False(IsUnavailable(err))
+}
+
+func TestClientTimeoutIsAuthorizedByFailureMode(t *testing.T) {
+	assert := assert.New(t)
+	server := newSlowCheckServer(300 * time.Millisecond)
+	defer server.Close()
+	client := NewClient(ClientConfig{
+		ApiKey:            "key",
+		ApiEndpoint:       server.URL,
+		AuthorizeEndpoint: server.URL,
+		HttpClient:        &http.Client{Timeout: 20 * time.Millisecond},
+	})
+
+	isAuthorized, err := client.Check(failOpenCheckParams(context.Background()))
+	assert.True(isAuthorized)
+	assert.NoError(err)
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"testing"
 
@@ -23,6 +25,16 @@ func setup() {
 	warrant.AuthorizeEndpoint = "https://api.warrant.dev"
 }
 
+func testConfig() warrant.ClientConfig {
+	return warrant.ClientConfig{
+		ApiKey:                  warrant.ApiKey,
+		ApiEndpoint:             warrant.ApiEndpoint,
+		AuthorizeEndpoint:       warrant.AuthorizeEndpoint,
+		SelfServiceDashEndpoint: warrant.SelfServiceDashEndpoint,
+		HttpClient:              warrant.HttpClient,
+	}
+}
+
 func TestCrudUsers(t *testing.T) {
 	setup()
 	assert := assert.New(t)
@@ -2452,3 +2464,60 @@ func TestBatchObjects(t *testing.T) {
 	}
 	assert.Len(fetchedObjects.Results, 0)
 }
+
+func TestDecisionSink(t *testing.T) {
+	setup()
+	assert := assert.New(t)
+
+	var decisionLog bytes.Buffer
+	config := testConfig()
+	config.DecisionSink = warrant.NewJSONLDecisionSink(&decisionLog)
+	client := warrant.NewClient(config)
+
+	newUser, err := user.Create(&warrant.UserParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkParams := &warrant.WarrantCheckParams{
+		RequestOptions: warrant.RequestOptions{
+			WarrantToken: "latest",
+		},
+		WarrantCheck: warrant.WarrantCheck{
+			Object: warrant.Object{
+				ObjectType: warrant.ObjectTypeTenant,
+				ObjectId:   "decision-tenant",
+			},
+			Relation: "member",
+			Subject: warrant.Subject{
+				ObjectType: warrant.ObjectTypeUser,
+				ObjectId:   newUser.UserId,
+			},
+		},
+	}
+	checkParams.SetMetadata("requestId", "req-1")
+	checkResult, err := client.Check(checkParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.False(checkResult)
+
+	var decision warrant.Decision
+	err = json.Unmarshal(decisionLog.Bytes(), &decision)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.False(decision.Authorized)
+	assert.Equal("latest", decision.WarrantToken)
+	assert.Equal(map[string]string{"requestId": "req-1"}, decision.Metadata)
+	assert.Len(decision.Checks, 1)
+	assert.Equal(warrant.ObjectTypeTenant, decision.Checks[0].ObjectType)
+	assert.Equal("decision-tenant", decision.Checks[0].ObjectId)
+	assert.Equal("member", decision.Checks[0].Relation)
+	assert.Equal(newUser.UserId, decision.Checks[0].Subject.ObjectId)
+
+	_, err = user.Delete(newUser.UserId)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,101 @@
+package warrant
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+type FailureMode string
+
+const (
+	FailureModeDeny       FailureMode = "deny"
+	FailureModeAllow      FailureMode = "allow"
+	FailureModeServeStale FailureMode = "serve-stale"
+)
+
+func resolveFailureMode(modes ...FailureMode) FailureMode {
+	for _, mode := range modes {
+		if mode != "" {
+			return mode
+		}
+	}
+	return FailureModeDeny
+}
+
+type staleCheckResult struct {
+	isAuthorized bool
+	checkedAt    time.Time
+}
+
+// StaleCheckCache remembers the last successful result of each check so that
+// FailureModeServeStale can answer while the Warrant API is unavailable.
+type StaleCheckCache struct {
+	mu         sync.Mutex
+	maxAge     time.Duration
+	maxEntries int
+	results    map[string]staleCheckResult
+}
+
+func NewStaleCheckCache(maxAge time.Duration, maxEntries int) *StaleCheckCache {
+	if maxEntries <= 0 {
+		maxEntries = 10000
+	}
+	return &StaleCheckCache{
+		maxAge:     maxAge,
+		maxEntries: maxEntries,
+		results:    make(map[string]staleCheckResult),
+	}
+}
+
+func (cache *StaleCheckCache) store(key string, isAuthorized bool) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if _, exists := cache.results[key]; !exists && len(cache.results) >= cache.maxEntries {
+		cache.evictOldest()
+	}
+	cache.results[key] = staleCheckResult{
+		isAuthorized: isAuthorized,
+		checkedAt:    time.Now(),
+	}
+}
+
+func (cache *StaleCheckCache) load(key string) (bool, bool) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	result, ok := cache.results[key]
+	if !ok {
+		return false, false
+	}
+	if cache.maxAge > 0 && time.Since(result.checkedAt) > cache.maxAge {
+		delete(cache.results, key)
+		return false, false
+	}
+	return result.isAuthorized, true
+}
+
+func (cache *StaleCheckCache) evictOldest() {
+	var oldestKey string
+	var oldest time.Time
+	for key, result := range cache.results {
+		if oldestKey == "" || result.checkedAt.Before(oldest) {
+			oldestKey = key
+			oldest = result.checkedAt
+		}
+	}
+	delete(cache.results, oldestKey)
+}
+
+func checkRequestKey(request *AccessCheckRequest) (string, error) {
+	key, err := json.Marshal(struct {
+		Op       string         `json:"op"`
+		Warrants []WarrantCheck `json:"warrants"`
+	}{
+		Op:       request.Op,
+		Warrants: request.Warrants,
+	})
+	if err != nil {
+		return "", err
+	}
+	return string(key), nil
+}
@@ -31,7 +31,7 @@ func (c Client) Create(params *warrant.FeatureParams) (*warrant.Feature, error)
 	if params.Meta != nil {
 		objectParams.Meta = params.Meta
 	}
-	object, err := object.Create(&objectParams)
+	object, err := object.NewClient(c.apiClient.Config).Create(&objectParams)
 	if err != nil {
 		return nil, err
 	}
@@ -55,7 +55,7 @@ func (c Client) Get(featureId string, params *warrant.FeatureParams) (*warrant.F
 		RequestOptions: params.RequestOptions,
 		Meta:           params.Meta,
 	}
-	object, err := object.Get(warrant.ObjectTypeFeature, featureId, &objectParams)
+	object, err := object.NewClient(c.apiClient.Config).Get(warrant.ObjectTypeFeature, featureId, &objectParams)
 	if err != nil {
 		return nil, err
 	}
@@ -79,7 +79,7 @@ func (c Client) Update(featureId string, params *warrant.FeatureParams) (*warran
 		RequestOptions: params.RequestOptions,
 		Meta:           params.Meta,
 	}
-	object, err := object.Update(warrant.ObjectTypeFeature, featureId, &objectParams)
+	object, err := object.NewClient(c.apiClient.Config).Update(warrant.ObjectTypeFeature, featureId, &objectParams)
 	if err != nil {
 		return nil, err
 	}
@@ -93,8 +93,48 @@ func Update(featureId string, params *warrant.FeatureParams) (*warrant.Feature,
 	return getClient().Update(featureId, params)
 }
 
+func (c Client) Upsert(params *warrant.FeatureParams) (*warrant.UpsertResult[warrant.Feature], error) {
+	if params == nil {
+		params = &warrant.FeatureParams{}
+	}
+	return object.UpsertAs(object.NewClient(c.apiClient.Config), &warrant.ObjectParams{
+		RequestOptions: params.RequestOptions,
+		ObjectType:     warrant.ObjectTypeFeature,
+		ObjectId:       params.FeatureId,
+		Meta:           params.Meta,
+	}, featureFromObject)
+}
+
+func Upsert(params *warrant.FeatureParams) (*warrant.UpsertResult[warrant.Feature], error) {
+	return getClient().Upsert(params)
+}
+
+func (c Client) BatchUpsert(params []warrant.FeatureParams) ([]warrant.UpsertResult[warrant.Feature], error) {
+	objectsToUpsert := make([]warrant.ObjectParams, 0)
+	for _, featureParam := range params {
+		objectsToUpsert = append(objectsToUpsert, warrant.ObjectParams{
+			RequestOptions: featureParam.RequestOptions,
+			ObjectType:     warrant.ObjectTypeFeature,
+			ObjectId:       featureParam.FeatureId,
+			Meta:           featureParam.Meta,
+		})
+	}
+	return object.BatchUpsertAs(object.NewClient(c.apiClient.Config), objectsToUpsert, featureFromObject)
+}
+
+func BatchUpsert(params []warrant.FeatureParams) ([]warrant.UpsertResult[warrant.Feature], error) {
+	return getClient().BatchUpsert(params)
+}
+
+func featureFromObject(featureObject warrant.Object) warrant.Feature {
+	return warrant.Feature{
+		FeatureId: featureObject.ObjectId,
+		Meta:      featureObject.Meta,
+	}
+}
+
 func (c Client) Delete(featureId string) (string, error) {
-	return object.Delete(warrant.ObjectTypeFeature, featureId)
+	return object.NewClient(c.apiClient.Config).Delete(warrant.ObjectTypeFeature, featureId)
 }
 
 func Delete(featureId string) (string, error) {
@@ -107,7 +147,7 @@ func (c Client) ListFeatures(listParams *warrant.ListFeatureParams) (warrant.Lis
 	}
 	var featuresListResponse warrant.ListResponse[warrant.Feature]
 
-	objectsListResponse, err := object.ListObjects(&warrant.ListObjectParams{
+	objectsListResponse, err := object.NewClient(c.apiClient.Config).ListObjects(&warrant.ListObjectParams{
 		ListParams: listParams.ListParams,
 		ObjectType: warrant.ObjectTypeFeature,
 	})
@@ -142,7 +182,7 @@ func (c Client) ListFeaturesForPricingTier(pricingTierId string, listParams *war
 	}
 	var featuresListResponse warrant.ListResponse[warrant.Feature]
 
-	queryResponse, err := warrant.Query(fmt.Sprintf("select feature where pricing-tier:%s is *", pricingTierId), &warrant.QueryParams{
+	queryResponse, err := warrant.NewClient(c.apiClient.Config).Query(fmt.Sprintf("select feature where pricing-tier:%s is *", pricingTierId), &warrant.QueryParams{
 		ListParams: listParams.ListParams,
 	})
 	if err != nil {
@@ -208,7 +248,7 @@ func (c Client) ListFeaturesForTenant(tenantId string, listParams *warrant.ListF
 	}
 	var featuresListResponse warrant.ListResponse[warrant.Feature]
 
-	queryResponse, err := warrant.Query(fmt.Sprintf("select feature where tenant:%s is *", tenantId), &warrant.QueryParams{
+	queryResponse, err := warrant.NewClient(c.apiClient.Config).Query(fmt.Sprintf("select feature where tenant:%s is *", tenantId), &warrant.QueryParams{
 		ListParams: listParams.ListParams,
 	})
 	if err != nil {
@@ -274,7 +314,7 @@ func (c Client) ListFeaturesForUser(userId string, listParams *warrant.ListFeatu
 	}
 	var featuresListResponse warrant.ListResponse[warrant.Feature]
 
-	queryResponse, err := warrant.Query(fmt.Sprintf("select feature where user:%s is *", userId), &warrant.QueryParams{
+	queryResponse, err := warrant.NewClient(c.apiClient.Config).Query(fmt.Sprintf("select feature where user:%s is *", userId), &warrant.QueryParams{
 		ListParams: listParams.ListParams,
 	})
 	if err != nil {
@@ -334,6 +374,93 @@ func RemoveFeatureFromUser(featureId string, userId string) (string, error) {
 	return getClient().RemoveFeatureFromUser(featureId, userId)
 }
 
+// AssignFeatureToSubject assigns a feature to any subject, including a
+// userset such as warrant.Userset("tenant", "acme", "admin").
+func (c Client) AssignFeatureToSubject(featureId string, subject warrant.Subject) (*warrant.Warrant, error) {
+	return warrant.NewClient(c.apiClient.Config).Create(&warrant.WarrantParams{
+		ObjectType: warrant.ObjectTypeFeature,
+		ObjectId:   featureId,
+		Relation:   "member",
+		Subject:    subject,
+	})
+}
+
+func AssignFeatureToSubject(featureId string, subject warrant.Subject) (*warrant.Warrant, error) {
+	return getClient().AssignFeatureToSubject(featureId, subject)
+}
+
+func (c Client) RemoveFeatureFromSubject(featureId string, subject warrant.Subject) (string, error) {
+	return warrant.NewClient(c.apiClient.Config).Delete(&warrant.WarrantParams{
+		ObjectType: warrant.ObjectTypeFeature,
+		ObjectId:   featureId,
+		Relation:   "member",
+		Subject:    subject,
+	})
+}
+
+func RemoveFeatureFromSubject(featureId string, subject warrant.Subject) (string, error) {
+	return getClient().RemoveFeatureFromSubject(featureId, subject)
+}
+
+func (c Client) ListUsersetsForFeature(featureId string, listParams *warrant.ListWarrantParams) (warrant.ListResponse[warrant.Subject], error) {
+	return warrant.NewClient(c.apiClient.Config).ListUsersets(warrant.ObjectTypeFeature, featureId, "member", listParams)
+}
+
+func ListUsersetsForFeature(featureId string, listParams *warrant.ListWarrantParams) (warrant.ListResponse[warrant.Subject], error) {
+	return getClient().ListUsersetsForFeature(featureId, listParams)
+}
+
+// TypedClient decodes feature meta into M on reads and validates and encodes
+// it on writes. The ObjectId of each result is the feature's id. It wraps
+// object.TypeClient.
+type TypedClient[M any] struct {
+	client object.TypeClient[M]
+}
+
+func Typed[M any](c Client) TypedClient[M] {
+	return TypedClient[M]{
+		client: object.TypedFor[M](object.NewClient(c.apiClient.Config), warrant.ObjectTypeFeature),
+	}
+}
+
+func (c TypedClient[M]) Get(featureId string, params *warrant.FeatureParams) (*warrant.ObjectOf[M], error) {
+	if params == nil {
+		params = &warrant.FeatureParams{}
+	}
+	return c.client.Get(featureId, params.RequestOptions)
+}
+
+func GetAs[M any](featureId string, params *warrant.FeatureParams) (*warrant.ObjectOf[M], error) {
+	return Typed[M](getClient()).Get(featureId, params)
+}
+
+func (c TypedClient[M]) List(listParams *warrant.ListFeatureParams) (warrant.ListResponse[warrant.ObjectOf[M]], error) {
+	if listParams == nil {
+		listParams = &warrant.ListFeatureParams{}
+	}
+	return c.client.List(listParams.ListParams)
+}
+
+func ListAs[M any](listParams *warrant.ListFeatureParams) (warrant.ListResponse[warrant.ObjectOf[M]], error) {
+	return Typed[M](getClient()).List(listParams)
+}
+
+func (c TypedClient[M]) Create(typedObject *warrant.ObjectOf[M]) (*warrant.ObjectOf[M], error) {
+	return c.client.Create(typedObject)
+}
+
+func CreateAs[M any](typedObject *warrant.ObjectOf[M]) (*warrant.ObjectOf[M], error) {
+	return Typed[M](getClient()).Create(typedObject)
+}
+
+func (c TypedClient[M]) Update(typedObject *warrant.ObjectOf[M]) (*warrant.ObjectOf[M], error) {
+	return c.client.Update(typedObject)
+}
+
+func UpdateAs[M any](typedObject *warrant.ObjectOf[M]) (*warrant.ObjectOf[M], error) {
+	return Typed[M](getClient()).Update(typedObject)
+}
+
 func getClient() Client {
 	config := warrant.ClientConfig{
 		ApiKey:                  warrant.ApiKey,
@@ -0,0 +1,238 @@
+package warrant
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+type HedgingSettings struct {
+	// How long to wait for the first response before sending a hedge.
+	Delay time.Duration
+	// When set, the delay is the given percentile (e.g. 0.95) of recently
+	// observed latencies instead, falling back to Delay until MinSamples
+	// latencies have been observed.
+	Percentile float64
+	MinSamples int
+	// The share of extra requests hedging may add, e.g. 0.05 for at most 5%.
+	MaxExtraLoad float64
+}
+
+// HedgingPolicy sends a second copy of a slow idempotent read (checks, queries,
+// gets and lists) and uses whichever response arrives first.
+type HedgingPolicy struct {
+	settings HedgingSettings
+
+	mu             sync.Mutex
+	tokens         float64
+	latencies      []time.Duration
+	nextLatency    int
+	percentileVal  time.Duration
+	samplesSinceUp int
+}
+
+const (
+	hedgingLatencyWindow   = 1000
+	hedgingRecomputeEvery  = 50
+	hedgingMaxBudgetTokens = 10
+)
+
+func NewHedgingPolicy(settings HedgingSettings) *HedgingPolicy {
+	if settings.MinSamples <= 0 {
+		settings.MinSamples = 100
+	}
+	if settings.MaxExtraLoad <= 0 {
+		settings.MaxExtraLoad = 0.05
+	}
+	return &HedgingPolicy{
+		settings:  settings,
+		latencies: make([]time.Duration, 0, hedgingLatencyWindow),
+	}
+}
+
+func isIdempotentRead(method string, path string) bool {
+	return method == http.MethodGet || (method == http.MethodPost && endpointGroupFor(path) == EndpointGroupCheck)
+}
+
+func (policy *HedgingPolicy) delay() time.Duration {
+	policy.mu.Lock()
+	defer policy.mu.Unlock()
+	if policy.settings.Percentile > 0 && len(policy.latencies) >= policy.settings.MinSamples {
+		return policy.percentileVal
+	}
+	return policy.settings.Delay
+}
+
+// recordRequest earns hedging budget for every request sent normally.
+func (policy *HedgingPolicy) recordRequest() {
+	policy.mu.Lock()
+	defer policy.mu.Unlock()
+	policy.tokens += policy.settings.MaxExtraLoad
+	if policy.tokens > hedgingMaxBudgetTokens {
+		policy.tokens = hedgingMaxBudgetTokens
+	}
+}
+
+func (policy *HedgingPolicy) acquireHedge() bool {
+	policy.mu.Lock()
+	defer policy.mu.Unlock()
+	if policy.tokens < 1 {
+		return false
+	}
+	policy.tokens--
+	return true
+}
+
+func (policy *HedgingPolicy) recordLatency(latency time.Duration) {
+	policy.mu.Lock()
+	defer policy.mu.Unlock()
+	if len(policy.latencies) < hedgingLatencyWindow {
+		policy.latencies = append(policy.latencies, latency)
+	} else {
+		policy.latencies[policy.nextLatency] = latency
+		policy.nextLatency = (policy.nextLatency + 1) % hedgingLatencyWindow
+	}
+
+	policy.samplesSinceUp++
+	if policy.settings.Percentile > 0 && len(policy.latencies) >= policy.settings.MinSamples && (policy.percentileVal == 0 || policy.samplesSinceUp >= hedgingRecomputeEvery) {
+		sorted := make([]time.Duration, len(policy.latencies))
+		copy(sorted, policy.latencies)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		index := int(policy.settings.Percentile * float64(len(sorted)-1))
+		policy.percentileVal = sorted[index]
+		policy.samplesSinceUp = 0
+	}
+}
+
+type hedgeAttempt struct {
+	index   int
+	resp    *http.Response
+	err     error
+	cancel  context.CancelFunc
+	latency time.Duration
+}
+
+// cancelOnClose releases an attempt's context once its body has been consumed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (body cancelOnClose) Close() error {
+	err := body.ReadCloser.Close()
+	body.cancel()
+	return err
+}
+
+func (client ApiClient) doHedged(ctx context.Context, policy *HedgingPolicy, newRequest func(ctx context.Context) (*http.Request, error)) (*http.Response, error) {
+	policy.recordRequest()
+	attempts := make(chan hedgeAttempt, 2)
+	cancels := make([]context.CancelFunc, 0, 2)
+	launch := func() error {
+		attemptCtx, cancel := context.WithCancel(ctx)
+		request, err := newRequest(attemptCtx)
+		if err != nil {
+			cancel()
+			return err
+		}
+		index := len(cancels)
+		cancels = append(cancels, cancel)
+		go func() {
+			start := time.Now()
+			resp, err := client.HttpClient.Do(request)
+			attempts <- hedgeAttempt{
+				index:   index,
+				resp:    resp,
+				err:     err,
+				cancel:  cancel,
+				latency: time.Since(start),
+			}
+		}()
+		return nil
+	}
+
+	if err := launch(); err != nil {
+		return nil, err
+	}
+	inFlight := 1
+
+	var hedgeTimer <-chan time.Time
+	if delay := policy.delay(); delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		hedgeTimer = timer.C
+	}
+
+	// An attempt that failed or got a 5xx response is only returned once every
+	// other attempt has failed too.
+	var fallback *hedgeAttempt
+	for {
+		select {
+		case <-hedgeTimer:
+			hedgeTimer = nil
+			if policy.acquireHedge() && launch() == nil {
+				inFlight++
+			}
+		case attempt := <-attempts:
+			inFlight--
+			if ctx.Err() == nil {
+				policy.recordLatency(attempt.latency)
+			}
+			if !attempt.wins() {
+				if fallback != nil {
+					fallback.release()
+				}
+				fallback = &attempt
+				if inFlight > 0 {
+					continue
+				}
+				attempt = *fallback
+				if attempt.err != nil {
+					attempt.cancel()
+					return nil, WrapError("Error making request", attempt.err)
+				}
+			} else if fallback != nil {
+				fallback.release()
+			}
+			if inFlight > 0 {
+				for index, cancel := range cancels {
+					if index != attempt.index {
+						cancel()
+					}
+				}
+				go discardHedgeAttempts(policy, attempts, inFlight)
+			}
+			attempt.resp.Body = cancelOnClose{
+				ReadCloser: attempt.resp.Body,
+				cancel:     attempt.cancel,
+			}
+			return attempt.resp, nil
+		}
+	}
+}
+
+// wins reports whether an attempt can be used without waiting for the others:
+// it got a response that was not a server error.
+func (attempt hedgeAttempt) wins() bool {
+	return attempt.err == nil && attempt.resp.StatusCode < http.StatusInternalServerError
+}
+
+func (attempt hedgeAttempt) release() {
+	if attempt.err == nil {
+		attempt.resp.Body.Close()
+	}
+	attempt.cancel()
+}
+
+func discardHedgeAttempts(policy *HedgingPolicy, attempts <-chan hedgeAttempt, remaining int) {
+	for ; remaining > 0; remaining-- {
+		attempt := <-attempts
+		if attempt.err == nil {
+			policy.recordLatency(attempt.latency)
+		}
+		attempt.release()
+	}
+}
@@ -0,0 +1,88 @@
+package warrant
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newHedgedServer answers the nth request (from 0) after delays[n] with
+// statuses[n].
+func newHedgedServer(delays []time.Duration, statuses []int) *httptest.Server {
+	var requests atomic.Int32
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := requests.Add(1) - 1
+		select {
+		case <-time.After(delays[n]):
+		case <-r.Context().Done():
+			return
+		}
+		w.WriteHeader(statuses[n])
+	}))
+}
+
+func doHedgedGet(client *ApiClient, policy *HedgingPolicy, url string) (*http.Response, error) {
+	return client.doHedged(context.Background(), policy, func(ctx context.Context) (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	})
+}
+
+func TestHedgingServerErrorDoesNotWin(t *testing.T) {
+	assert := assert.New(t)
+	server := newHedgedServer(
+		[]time.Duration{40 * time.Millisecond, 60 * time.Millisecond},
+		[]int{http.StatusServiceUnavailable, http.StatusOK},
+	)
+	defer server.Close()
+	policy := NewHedgingPolicy(HedgingSettings{Delay: 10 * time.Millisecond, MaxExtraLoad: 1})
+	client := NewApiClient(ClientConfig{})
+
+	resp, err := doHedgedGet(client, policy, server.URL)
+	if assert.NoError(err) {
+		defer resp.Body.Close()
+		assert.Equal(http.StatusOK, resp.StatusCode)
+	}
+	assert.Len(policy.latencies, 2)
+}
+
+func TestHedgingReturnsServerErrorWhenEveryAttemptFails(t *testing.T) {
+	assert := assert.New(t)
+	server := newHedgedServer(
+		[]time.Duration{40 * time.Millisecond, 20 * time.Millisecond},
+		[]int{http.StatusBadGateway, http.StatusServiceUnavailable},
+	)
+	defer server.Close()
+	policy := NewHedgingPolicy(HedgingSettings{Delay: 10 * time.Millisecond, MaxExtraLoad: 1})
+	client := NewApiClient(ClientConfig{})
+
+	resp, err := doHedgedGet(client, policy, server.URL)
+	if assert.NoError(err) {
+		defer resp.Body.Close()
+		assert.Equal(http.StatusBadGateway, resp.StatusCode)
+	}
+	assert.Len(policy.latencies, 2)
+}
+
+func TestHedgingClientErrorWins(t *testing.T) {
+	assert := assert.New(t)
+	server := newHedgedServer(
+		[]time.Duration{20 * time.Millisecond, 200 * time.Millisecond},
+		[]int{http.StatusNotFound, http.StatusOK},
+	)
+	defer server.Close()
+	policy := NewHedgingPolicy(HedgingSettings{Delay: 10 * time.Millisecond, MaxExtraLoad: 1})
+	client := NewApiClient(ClientConfig{})
+
+	start := time.Now()
+	resp, err := doHedgedGet(client, policy, server.URL)
+	if assert.NoError(err) {
+		defer resp.Body.Close()
+		assert.Equal(http.StatusNotFound, resp.StatusCode)
+	}
+	assert.Less(time.Since(start), 200*time.Millisecond)
+}
@@ -0,0 +1,160 @@
+package warrant
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	OutcomeSuccess     = "success"
+	OutcomeClientError = "client_error"
+	OutcomeUnavailable = "unavailable"
+	OutcomeError       = "error"
+)
+
+type InstrumentationTags struct {
+	Operation  string
+	ObjectType string
+	Relation   string
+	Outcome    string
+}
+
+type Span interface {
+	End(err error)
+}
+
+// Instrumentation receives a span, a latency measurement and, on failure, an
+// error count for every request made by the SDK. Outcome is only set on the
+// tags passed to RecordLatency and IncError. StartSpan is given the request's
+// context and the context it returns is the one the request is sent with, so
+// a tracing adapter can make its span the parent of anything below it.
+type Instrumentation interface {
+	StartSpan(ctx context.Context, tags InstrumentationTags) (context.Context, Span)
+	RecordLatency(tags InstrumentationTags, latency time.Duration)
+	IncError(tags InstrumentationTags, err error)
+}
+
+func outcomeOf(err error) string {
+	if err == nil {
+		return OutcomeSuccess
+	}
+	if IsUnavailable(err) {
+		return OutcomeUnavailable
+	}
+	var warrantErr Error
+	if errors.As(err, &warrantErr) && warrantErr.StatusCode >= 400 {
+		return OutcomeClientError
+	}
+	return OutcomeError
+}
+
+func instrumentationTags(operation string, path string, payload interface{}) InstrumentationTags {
+	tags := InstrumentationTags{
+		Operation: operation,
+	}
+	switch params := payload.(type) {
+	case *WarrantParams:
+		tags.ObjectType = params.ObjectType
+		tags.Relation = params.Relation
+	case []WarrantParams:
+		if len(params) > 0 {
+			tags.ObjectType = params[0].ObjectType
+			tags.Relation = params[0].Relation
+		}
+	case *ObjectParams:
+		tags.ObjectType = params.ObjectType
+	case []ObjectParams:
+		if len(params) > 0 {
+			tags.ObjectType = params[0].ObjectType
+		}
+	case *ObjectTypeParams:
+		tags.ObjectType = params.Type
+	case *AccessCheckRequest:
+		if len(params.Warrants) > 0 && params.Warrants[0].Object != nil {
+			tags.ObjectType = params.Warrants[0].Object.GetObjectType()
+			tags.Relation = params.Warrants[0].Relation
+		}
+	}
+	if tags.ObjectType != "" {
+		return tags
+	}
+
+	rawPath, rawQuery, _ := strings.Cut(path, "?")
+	segments := strings.Split(strings.Trim(strings.TrimPrefix(rawPath, "/v2/"), "/"), "/")
+	if len(segments) > 1 && (segments[0] == "objects" || segments[0] == "object-types") {
+		tags.ObjectType = segments[1]
+	}
+	if queryValues, err := url.ParseQuery(rawQuery); err == nil {
+		if tags.ObjectType == "" {
+			tags.ObjectType = queryValues.Get("objectType")
+		}
+		if tags.Relation == "" {
+			tags.Relation = queryValues.Get("relation")
+		}
+	}
+	return tags
+}
+
+type SlogInstrumentation struct {
+	logger *slog.Logger
+}
+
+func NewSlogInstrumentation(logger *slog.Logger) *SlogInstrumentation {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &SlogInstrumentation{
+		logger: logger,
+	}
+}
+
+type slogSpan struct {
+	ctx    context.Context
+	logger *slog.Logger
+	tags   InstrumentationTags
+	start  time.Time
+}
+
+func (span slogSpan) End(err error) {
+	span.logger.LogAttrs(span.ctx, slog.LevelDebug, "warrant span",
+		append(span.tags.attrs(), slog.Duration("duration", time.Since(span.start)))...)
+}
+
+func (instrumentation *SlogInstrumentation) StartSpan(ctx context.Context, tags InstrumentationTags) (context.Context, Span) {
+	return ctx, slogSpan{
+		ctx:    ctx,
+		logger: instrumentation.logger,
+		tags:   tags,
+		start:  time.Now(),
+	}
+}
+
+func (instrumentation *SlogInstrumentation) RecordLatency(tags InstrumentationTags, latency time.Duration) {
+	instrumentation.logger.LogAttrs(context.Background(), slog.LevelDebug, "warrant request",
+		append(tags.attrs(), slog.Duration("latency", latency))...)
+}
+
+func (instrumentation *SlogInstrumentation) IncError(tags InstrumentationTags, err error) {
+	instrumentation.logger.LogAttrs(context.Background(), slog.LevelWarn, "warrant request failed",
+		append(tags.attrs(), slog.String("error", err.Error()))...)
+}
+
+func (tags InstrumentationTags) attrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("operation", tags.Operation),
+	}
+	if tags.ObjectType != "" {
+		attrs = append(attrs, slog.String("objectType", tags.ObjectType))
+	}
+	if tags.Relation != "" {
+		attrs = append(attrs, slog.String("relation", tags.Relation))
+	}
+	if tags.Outcome != "" {
+		attrs = append(attrs, slog.String("outcome", tags.Outcome))
+	}
+	return attrs
+}
@@ -0,0 +1,73 @@
+package warrant
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type spanContextKey struct{}
+
+type fakeSpan struct {
+	ended bool
+	err   error
+}
+
+func (span *fakeSpan) End(err error) {
+	span.ended = true
+	span.err = err
+}
+
+type fakeInstrumentation struct {
+	parent context.Context
+	span   *fakeSpan
+	tags   []InstrumentationTags
+}
+
+func (instrumentation *fakeInstrumentation) StartSpan(ctx context.Context, tags InstrumentationTags) (context.Context, Span) {
+	instrumentation.parent = ctx
+	instrumentation.span = &fakeSpan{}
+	return context.WithValue(ctx, spanContextKey{}, instrumentation.span), instrumentation.span
+}
+
+func (instrumentation *fakeInstrumentation) RecordLatency(tags InstrumentationTags, latency time.Duration) {
+	instrumentation.tags = append(instrumentation.tags, tags)
+}
+
+func (instrumentation *fakeInstrumentation) IncError(tags InstrumentationTags, err error) {}
+
+func TestInstrumentationSpanContextReachesRequest(t *testing.T) {
+	assert := assert.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"objectType":"role","objectId":"admin"}`))
+	}))
+	defer server.Close()
+
+	type callerKey struct{}
+	instrumentation := &fakeInstrumentation{}
+	var requestSpan interface{}
+	client := NewApiClient(ClientConfig{
+		ApiEndpoint:     server.URL,
+		Instrumentation: instrumentation,
+		Interceptors: []Interceptor{func(call *Call, next Invoker) (*Response, error) {
+			requestSpan = call.Request.Context().Value(spanContextKey{})
+			return next(call)
+		}},
+	})
+	options := &RequestOptions{}
+	options.SetContext(context.WithValue(context.Background(), callerKey{}, "caller"))
+	resp, err := client.MakeRequest("GET", "/v2/objects/role/admin", nil, options)
+	if assert.NoError(err) {
+		resp.Body.Close()
+	}
+
+	assert.Equal("caller", instrumentation.parent.Value(callerKey{}))
+	assert.Same(instrumentation.span, requestSpan)
+	assert.True(instrumentation.span.ended)
+	assert.NoError(instrumentation.span.err)
+	assert.Equal([]InstrumentationTags{{Operation: "objects.get", ObjectType: "role", Outcome: OutcomeSuccess}}, instrumentation.tags)
+}
@@ -0,0 +1,132 @@
+package warrant
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// Call describes a single request made by the SDK as it passes through the
+// interceptor chain. Operation is the logical name of the SDK call, such as
+// "warrants.create", "objects.get" or "check".
+type Call struct {
+	Operation string
+	Request   *http.Request
+	Options   *RequestOptions
+}
+
+// Response is a completed response as seen by interceptors. The body has
+// already been read into Data, so interceptors can decode it without consuming
+// it for the SDK. Failed calls never produce a Response; next returns an Error
+// carrying the status code and message instead.
+type Response struct {
+	*http.Response
+	Data []byte
+}
+
+// Decode unmarshals the response body into v.
+func (response *Response) Decode(v interface{}) error {
+	if err := json.Unmarshal(response.Data, v); err != nil {
+		return WrapError("Invalid response from server", err)
+	}
+	return nil
+}
+
+// httpResponse returns the underlying response with its body reset to Data.
+func (response *Response) httpResponse() *http.Response {
+	resp := response.Response
+	if resp == nil {
+		resp = &http.Response{StatusCode: http.StatusOK, Header: make(http.Header)}
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(response.Data))
+	return resp
+}
+
+type Invoker func(call *Call) (*Response, error)
+
+// Interceptor wraps every request made through an ApiClient. It may modify
+// call.Request before calling next, inspect the response or error returned by
+// next, or return its own response without calling next at all.
+type Interceptor func(call *Call, next Invoker) (*Response, error)
+
+func chainInterceptors(interceptors []Interceptor, invoker Invoker) Invoker {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor := interceptors[i]
+		next := invoker
+		invoker = func(call *Call) (*Response, error) {
+			return interceptor(call, next)
+		}
+	}
+	return invoker
+}
+
+// cloneRequest copies a request for another attempt, including a fresh body.
+func cloneRequest(ctx context.Context, request *http.Request) (*http.Request, error) {
+	clone := request.Clone(ctx)
+	if request.GetBody != nil {
+		body, err := request.GetBody()
+		if err != nil {
+			return nil, WrapError("Unable to create request", err)
+		}
+		clone.Body = body
+	}
+	return clone, nil
+}
+
+var operationResources = map[string]string{
+	"check":        "check",
+	"query":        "query",
+	"warrants":     "warrants",
+	"objects":      "objects",
+	"object-types": "objectTypes",
+	"sessions":     "sessions",
+}
+
+func OperationName(method string, path string, payload interface{}) string {
+	path = strings.TrimPrefix(path, "/v2/")
+	if i := strings.Index(path, "?"); i >= 0 {
+		path = path[:i]
+	}
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	resource, ok := operationResources[segments[0]]
+	if !ok {
+		resource = segments[0]
+	}
+	if resource == "check" || resource == "query" {
+		return resource
+	}
+
+	hasId := len(segments) > 1
+	isBatch := payload != nil && reflect.TypeOf(payload).Kind() == reflect.Slice
+	var action string
+	switch method {
+	case http.MethodGet:
+		if hasId {
+			action = "get"
+		} else {
+			action = "list"
+		}
+	case http.MethodPost:
+		action = "create"
+		if isBatch {
+			action = "batchCreate"
+		}
+	case http.MethodPut:
+		action = "update"
+		if isBatch {
+			action = "batchUpdate"
+		}
+	case http.MethodDelete:
+		action = "delete"
+		if isBatch {
+			action = "batchDelete"
+		}
+	default:
+		action = strings.ToLower(method)
+	}
+	return resource + "." + action
+}
@@ -0,0 +1,120 @@
+package warrant
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func decodeTestResponse(resp *http.Response, v interface{}) error {
+	defer resp.Body.Close()
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func TestOperationName(t *testing.T) {
+	tests := []struct {
+		method    string
+		path      string
+		payload   interface{}
+		operation string
+	}{
+		{"POST", "/v2/check", AccessCheckRequest{}, "check"},
+		{"GET", "/v2/query?q=select%20role", nil, "query"},
+		{"POST", "/v2/warrants", WarrantParams{}, "warrants.create"},
+		{"POST", "/v2/warrants", []WarrantParams{}, "warrants.batchCreate"},
+		{"DELETE", "/v2/warrants", []WarrantParams{}, "warrants.batchDelete"},
+		{"GET", "/v2/objects?objectType=role", nil, "objects.list"},
+		{"GET", "/v2/objects/role/admin", nil, "objects.get"},
+		{"PUT", "/v2/objects/role/admin", ObjectParams{}, "objects.update"},
+		{"PUT", "/v2/object-types/role", ObjectTypeParams{}, "objectTypes.update"},
+		{"PUT", "/v2/object-types", []ObjectTypeParams{}, "objectTypes.batchUpdate"},
+		{"PUT", "/v2/object-types", ObjectTypeParams{}, "objectTypes.update"},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.operation, OperationName(test.method, test.path, test.payload), "%s %s", test.method, test.path)
+	}
+}
+
+func TestInterceptorSeesDecodedResponse(t *testing.T) {
+	assert := assert.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"objectType":"role","objectId":"admin"}`))
+	}))
+	defer server.Close()
+
+	var seen Object
+	client := NewApiClient(ClientConfig{
+		ApiEndpoint: server.URL,
+		Interceptors: []Interceptor{func(call *Call, next Invoker) (*Response, error) {
+			resp, err := next(call)
+			if err == nil {
+				assert.NoError(resp.Decode(&seen))
+			}
+			return resp, err
+		}},
+	})
+	resp, err := client.MakeRequest("GET", "/v2/objects/role/admin", nil, &RequestOptions{})
+	if assert.NoError(err) {
+		var object Object
+		assert.NoError(decodeTestResponse(resp, &object))
+		assert.Equal(Object{ObjectType: "role", ObjectId: "admin"}, object)
+	}
+	assert.Equal(Object{ObjectType: "role", ObjectId: "admin"}, seen)
+}
+
+func TestInterceptorSeesDecodedError(t *testing.T) {
+	assert := assert.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message":"Object not found"}`))
+	}))
+	defer server.Close()
+
+	var seen error
+	client := NewApiClient(ClientConfig{
+		ApiEndpoint: server.URL,
+		Interceptors: []Interceptor{func(call *Call, next Invoker) (*Response, error) {
+			resp, err := next(call)
+			seen = err
+			return resp, err
+		}},
+	})
+	_, err := client.MakeRequest("GET", "/v2/objects/role/admin", nil, &RequestOptions{})
+	assert.Error(err)
+	var warrantErr Error
+	if assert.ErrorAs(seen, &warrantErr) {
+		assert.Equal(http.StatusNotFound, warrantErr.StatusCode)
+		assert.Contains(warrantErr.Message, "Object not found")
+	}
+}
+
+func TestInterceptorCanAnswerWithoutCallingNext(t *testing.T) {
+	assert := assert.New(t)
+	client := NewApiClient(ClientConfig{
+		ApiEndpoint: "http://localhost:0",
+		Interceptors: []Interceptor{func(call *Call, next Invoker) (*Response, error) {
+			return &Response{Data: []byte(`{"objectType":"role","objectId":"cached"}`)}, nil
+		}},
+	})
+	resp, err := client.MakeRequest("GET", "/v2/objects/role/cached", nil, &RequestOptions{})
+	if assert.NoError(err) {
+		var object Object
+		assert.NoError(decodeTestResponse(resp, &object))
+		assert.Equal("cached", object.ObjectId)
+	}
+}
+
+func TestInterceptorReturningNothingIsAnError(t *testing.T) {
+	client := NewApiClient(ClientConfig{
+		ApiEndpoint: "http://localhost:0",
+		Interceptors: []Interceptor{func(call *Call, next Invoker) (*Response, error) {
+			return nil, nil
+		}},
+	})
+	resp, err := client.MakeRequest("GET", "/v2/objects/role/cached", nil, &RequestOptions{})
+	assert.Nil(t, resp)
+	assert.ErrorContains(t, err, "Interceptor returned no response")
+}
@@ -0,0 +1,114 @@
+package warrant
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const redacted = "[REDACTED]"
+
+var sensitiveHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+}
+
+var sensitiveNameMarkers = []string{"token", "apikey", "api-key", "secret", "password"}
+
+// isSensitiveName reports whether a header or query parameter may carry a
+// credential. Warrant-Token is a consistency token, not a credential.
+func isSensitiveName(name string) bool {
+	name = strings.ToLower(name)
+	if name == "warrant-token" || name == "warranttoken" {
+		return false
+	}
+	for _, marker := range sensitiveNameMarkers {
+		if strings.Contains(name, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactedHeaders logs request or response headers with credentials such as
+// the API key and session tokens masked.
+type redactedHeaders http.Header
+
+func (headers redactedHeaders) LogValue() slog.Value {
+	attrs := make([]slog.Attr, 0, len(headers))
+	for name, values := range headers {
+		value := strings.Join(values, ", ")
+		if sensitiveHeaders[http.CanonicalHeaderKey(name)] || isSensitiveName(name) {
+			value = redacted
+		}
+		attrs = append(attrs, slog.String(name, value))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+func redactedQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return redacted
+	}
+	for name := range values {
+		if isSensitiveName(name) {
+			values[name] = []string{redacted}
+		}
+	}
+	return values.Encode()
+}
+
+// redactedError masks credentials in the query string that network errors
+// include as part of the request URL.
+func redactedError(err error, rawQuery string) string {
+	message := err.Error()
+	if rawQuery == "" {
+		return message
+	}
+	return strings.ReplaceAll(message, rawQuery, redactedQuery(rawQuery))
+}
+
+func (client ApiClient) logCall(call *Call, resp *http.Response, err error, duration time.Duration) {
+	logger := client.Config.Logger
+	if logger == nil || !logger.Enabled(context.Background(), slog.LevelDebug) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("operation", call.Operation),
+		slog.String("method", call.Request.Method),
+		slog.String("path", call.Request.URL.Path),
+		slog.Duration("duration", duration),
+		slog.Any("requestHeaders", redactedHeaders(call.Request.Header)),
+	}
+	if query := redactedQuery(call.Request.URL.RawQuery); query != "" {
+		attrs = append(attrs, slog.String("query", query))
+	}
+	if warrantToken := call.Request.Header.Get("Warrant-Token"); warrantToken != "" {
+		attrs = append(attrs, slog.String("requestWarrantToken", warrantToken))
+	}
+	if resp != nil {
+		attrs = append(attrs, slog.Int("status", resp.StatusCode))
+		if warrantToken := resp.Header.Get("Warrant-Token"); warrantToken != "" {
+			attrs = append(attrs, slog.String("warrantToken", warrantToken))
+		}
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", redactedError(err, call.Request.URL.RawQuery)))
+		var warrantErr Error
+		if errors.As(err, &warrantErr) && warrantErr.StatusCode != 0 {
+			attrs = append(attrs, slog.Int("status", warrantErr.StatusCode))
+		}
+	}
+	logger.LogAttrs(context.Background(), slog.LevelDebug, "warrant api request", attrs...)
+}
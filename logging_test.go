@@ -0,0 +1,75 @@
+package warrant
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testSecret = "s3cr3t-value"
+
+func newDebugLogger() (*slog.Logger, *bytes.Buffer) {
+	output := &bytes.Buffer{}
+	return slog.New(slog.NewJSONHandler(output, &slog.HandlerOptions{Level: slog.LevelDebug})), output
+}
+
+func secretHeaders(call *Call, next Invoker) (*Response, error) {
+	call.Request.Header.Set("X-Session-Token", testSecret)
+	call.Request.Header.Set("X-Api-Key", testSecret)
+	call.Request.Header.Set("Cookie", "session="+testSecret)
+	call.Request.Header.Set("Warrant-Token", "consistency-token")
+	return next(call)
+}
+
+func TestLoggingRedactsCredentials(t *testing.T) {
+	assert := assert.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"objectType":"role","objectId":"admin"}`))
+	}))
+	defer server.Close()
+
+	logger, output := newDebugLogger()
+	client := NewApiClient(ClientConfig{
+		ApiKey:       testSecret,
+		ApiEndpoint:  server.URL,
+		Logger:       logger,
+		Interceptors: []Interceptor{secretHeaders},
+	})
+	_, err := client.MakeRequest("GET", "/v2/objects/role/admin?sessionToken="+testSecret+"&apiKey="+testSecret+"&limit=10", nil, &RequestOptions{})
+	assert.NoError(err)
+
+	logged := output.String()
+	assert.Contains(logged, "warrant api request")
+	assert.NotContains(logged, testSecret)
+	assert.Contains(logged, redacted)
+	assert.Contains(logged, "limit=10")
+	assert.Contains(logged, "consistency-token")
+}
+
+func TestLoggingRedactsCredentialsInErrors(t *testing.T) {
+	assert := assert.New(t)
+	logger, output := newDebugLogger()
+	client := NewApiClient(ClientConfig{
+		ApiKey:      testSecret,
+		ApiEndpoint: "http://127.0.0.1:1",
+		Logger:      logger,
+	})
+	_, err := client.MakeRequest("GET", "/v2/objects/role/admin?sessionToken="+testSecret, nil, &RequestOptions{})
+	assert.Error(err)
+
+	logged := output.String()
+	assert.Contains(logged, "warrant api request")
+	assert.NotContains(logged, testSecret)
+}
+
+func TestLoggingIsSkippedAboveDebug(t *testing.T) {
+	output := &bytes.Buffer{}
+	logger := slog.New(slog.NewJSONHandler(output, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	client := NewApiClient(ClientConfig{ApiKey: testSecret, ApiEndpoint: "http://127.0.0.1:1", Logger: logger})
+	client.MakeRequest("GET", "/v2/objects/role/admin", nil, &RequestOptions{})
+	assert.Empty(t, output.String())
+}
@@ -0,0 +1,339 @@
+package warrant
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ObjectOf is an object whose meta is decoded into M. Meta keys that M does
+// not declare are kept in UnknownMeta and written back on update, so that
+// fields added by other writers are not lost.
+type ObjectOf[M any] struct {
+	ObjectType  string
+	ObjectId    string
+	Meta        M
+	UnknownMeta map[string]interface{}
+}
+
+func (object ObjectOf[M]) GetObjectType() string {
+	return object.ObjectType
+}
+
+func (object ObjectOf[M]) GetObjectId() string {
+	return object.ObjectId
+}
+
+// Params validates and encodes the object's meta for a create or update.
+func (object ObjectOf[M]) Params() (*ObjectParams, error) {
+	meta, err := EncodeMeta(object.Meta, object.UnknownMeta)
+	if err != nil {
+		return nil, err
+	}
+	return &ObjectParams{
+		ObjectType: object.ObjectType,
+		ObjectId:   object.ObjectId,
+		Meta:       meta,
+	}, nil
+}
+
+func DecodeObject[M any](object Object) (*ObjectOf[M], error) {
+	meta, unknownMeta, err := DecodeMeta[M](object.Meta)
+	if err != nil {
+		return nil, err
+	}
+	return &ObjectOf[M]{
+		ObjectType:  object.ObjectType,
+		ObjectId:    object.ObjectId,
+		Meta:        meta,
+		UnknownMeta: unknownMeta,
+	}, nil
+}
+
+// DecodeMeta decodes meta into M and returns the keys M does not declare.
+func DecodeMeta[M any](meta map[string]interface{}) (M, map[string]interface{}, error) {
+	var decoded M
+	if len(meta) == 0 {
+		return decoded, nil, nil
+	}
+	metaJson, err := json.Marshal(meta)
+	if err != nil {
+		return decoded, nil, WrapError("Invalid object meta", err)
+	}
+	err = json.Unmarshal(metaJson, &decoded)
+	if err != nil {
+		return decoded, nil, WrapError("Unable to decode object meta", err)
+	}
+
+	knownFields, ok := metaFields(reflect.TypeOf((*M)(nil)).Elem())
+	if !ok {
+		return decoded, nil, nil
+	}
+	var unknownMeta map[string]interface{}
+	for key, value := range meta {
+		if knownFields[key] {
+			continue
+		}
+		if unknownMeta == nil {
+			unknownMeta = make(map[string]interface{})
+		}
+		unknownMeta[key] = value
+	}
+	return decoded, unknownMeta, nil
+}
+
+// EncodeMeta validates meta and encodes it along with any unknown keys.
+func EncodeMeta[M any](meta M, unknownMeta map[string]interface{}) (map[string]interface{}, error) {
+	err := ValidateMeta(meta)
+	if err != nil {
+		return nil, err
+	}
+	metaJson, err := json.Marshal(meta)
+	if err != nil {
+		return nil, WrapError("Invalid object meta", err)
+	}
+	var encoded map[string]interface{}
+	err = json.Unmarshal(metaJson, &encoded)
+	if err != nil {
+		return nil, WrapError("Object meta must encode to a JSON object", err)
+	}
+
+	knownFields, _ := metaFields(reflect.TypeOf((*M)(nil)).Elem())
+	for key, value := range unknownMeta {
+		if _, ok := encoded[key]; ok || knownFields[key] {
+			continue
+		}
+		if encoded == nil {
+			encoded = make(map[string]interface{})
+		}
+		encoded[key] = value
+	}
+	return encoded, nil
+}
+
+// metaFields returns the JSON keys declared by a struct type, or false if the
+// type is not a struct and so accepts any key.
+func metaFields(t reflect.Type) (map[string]bool, bool) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, false
+	}
+	fields := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, embedded := jsonFieldName(field)
+		if embedded {
+			embeddedFields, _ := metaFields(field.Type)
+			for embeddedName := range embeddedFields {
+				fields[embeddedName] = true
+			}
+			continue
+		}
+		if name != "" {
+			fields[name] = true
+		}
+	}
+	return fields, true
+}
+
+// jsonFieldName returns the JSON key of a struct field, or reports that the
+// field is an embedded struct whose fields are promoted.
+func jsonFieldName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	if field.Anonymous && name == "" {
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		if fieldType.Kind() == reflect.Struct {
+			return "", true
+		}
+	}
+	if !field.IsExported() {
+		return "", false
+	}
+	if name == "" {
+		name = field.Name
+	}
+	return name, false
+}
+
+type FieldError struct {
+	Field   string
+	Rule    string
+	Message string
+}
+
+// ValidationError lists every field of an object's meta that failed
+// validation.
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (err ValidationError) Error() string {
+	messages := make([]string, 0, len(err.Errors))
+	for _, fieldErr := range err.Errors {
+		field := fieldErr.Field
+		if field == "" {
+			field = "meta"
+		}
+		messages = append(messages, fmt.Sprintf("%s %s", field, fieldErr.Message))
+	}
+	return fmt.Sprintf("Warrant error: invalid object meta: %s", strings.Join(messages, "; "))
+}
+
+// MetaValidator can be implemented by meta types to add validation beyond
+// struct tags.
+type MetaValidator interface {
+	Validate() error
+}
+
+// ValidateMeta checks the validate struct tags of meta and, if it implements
+// MetaValidator, its Validate method. Supported rules are required,
+// omitempty, min=N and max=N (the length of strings, slices and maps, or the
+// value of numbers) and oneof=a b c.
+func ValidateMeta(meta interface{}) error {
+	fieldErrors := make([]FieldError, 0)
+	validateValue(reflect.ValueOf(meta), "", &fieldErrors)
+	if len(fieldErrors) > 0 {
+		return ValidationError{
+			Errors: fieldErrors,
+		}
+	}
+	if validator, ok := meta.(MetaValidator); ok {
+		return validator.Validate()
+	}
+	return nil
+}
+
+func validateValue(value reflect.Value, path string, fieldErrors *[]FieldError) {
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return
+		}
+		value = value.Elem()
+	}
+	switch value.Kind() {
+	case reflect.Struct:
+		valueType := value.Type()
+		for i := 0; i < valueType.NumField(); i++ {
+			field := valueType.Field(i)
+			name, embedded := jsonFieldName(field)
+			if embedded {
+				validateValue(value.Field(i), path, fieldErrors)
+				continue
+			}
+			if name == "" {
+				continue
+			}
+			fieldPath := name
+			if path != "" {
+				fieldPath = path + "." + name
+			}
+			if rules := field.Tag.Get("validate"); rules != "" {
+				validateRules(value.Field(i), fieldPath, rules, fieldErrors)
+			}
+			validateValue(value.Field(i), fieldPath, fieldErrors)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			validateValue(value.Index(i), fmt.Sprintf("%s[%d]", path, i), fieldErrors)
+		}
+	}
+}
+
+func validateRules(value reflect.Value, path string, rules string, fieldErrors *[]FieldError) {
+	for _, rule := range strings.Split(rules, ",") {
+		rule, param, _ := strings.Cut(rule, "=")
+		if rule == "omitempty" {
+			if value.IsZero() {
+				return
+			}
+			continue
+		}
+		if message := checkRule(value, rule, param); message != "" {
+			*fieldErrors = append(*fieldErrors, FieldError{
+				Field:   path,
+				Rule:    rule,
+				Message: message,
+			})
+		}
+	}
+}
+
+// checkRule returns a message describing how value breaks the rule, or ""
+// if it doesn't.
+func checkRule(value reflect.Value, rule string, param string) string {
+	if rule == "required" {
+		if value.IsZero() {
+			return "is required"
+		}
+		return ""
+	}
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return ""
+		}
+		value = value.Elem()
+	}
+
+	switch rule {
+	case "min", "max":
+		limit, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return fmt.Sprintf("has an invalid %s rule %q", rule, param)
+		}
+		size, isLength, ok := sizeOf(value)
+		if !ok {
+			return ""
+		}
+		if rule == "min" && size < limit {
+			if isLength {
+				return fmt.Sprintf("must have a length of at least %s", param)
+			}
+			return fmt.Sprintf("must be at least %s", param)
+		}
+		if rule == "max" && size > limit {
+			if isLength {
+				return fmt.Sprintf("must have a length of at most %s", param)
+			}
+			return fmt.Sprintf("must be at most %s", param)
+		}
+	case "oneof":
+		actual := fmt.Sprint(value.Interface())
+		for _, allowed := range strings.Fields(param) {
+			if actual == allowed {
+				return ""
+			}
+		}
+		return fmt.Sprintf("must be one of [%s]", param)
+	default:
+		return fmt.Sprintf("has an unknown validation rule %q", rule)
+	}
+	return ""
+}
+
+func sizeOf(value reflect.Value) (float64, bool, bool) {
+	switch value.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(value.String())), true, true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(value.Len()), true, true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int()), false, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint()), false, true
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), false, true
+	}
+	return 0, false, false
+}
@@ -0,0 +1,143 @@
+package warrant
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type auditMeta struct {
+	CreatedBy string `json:"createdBy" validate:"required"`
+}
+
+type documentMeta struct {
+	auditMeta
+	Title  string   `json:"title" validate:"required,max=10"`
+	Status string   `json:"status,omitempty" validate:"omitempty,oneof=draft published"`
+	Tags   []string `json:"tags,omitempty" validate:"max=2"`
+	Pages  int      `json:"pages" validate:"min=1"`
+	Secret string   `json:"-"`
+}
+
+type checkedMeta struct {
+	Name string `json:"name"`
+}
+
+func (meta checkedMeta) Validate() error {
+	if meta.Name == "root" {
+		return errors.New("name is reserved")
+	}
+	return nil
+}
+
+func TestValidateMeta(t *testing.T) {
+	tests := []struct {
+		name   string
+		meta   interface{}
+		fields []string
+		rules  []string
+	}{
+		{"valid", documentMeta{auditMeta: auditMeta{CreatedBy: "1"}, Title: "Readme", Status: "draft", Pages: 1}, nil, nil},
+		{"required", documentMeta{Title: "Readme", Pages: 1}, []string{"createdBy"}, []string{"required"}},
+		{"max length", documentMeta{auditMeta: auditMeta{CreatedBy: "1"}, Title: "A very long title", Pages: 1}, []string{"title"}, []string{"max"}},
+		{"omitempty skips empty", documentMeta{auditMeta: auditMeta{CreatedBy: "1"}, Title: "Readme", Pages: 1}, nil, nil},
+		{"oneof", documentMeta{auditMeta: auditMeta{CreatedBy: "1"}, Title: "Readme", Status: "archived", Pages: 1}, []string{"status"}, []string{"oneof"}},
+		{"max items", documentMeta{auditMeta: auditMeta{CreatedBy: "1"}, Title: "Readme", Tags: []string{"a", "b", "c"}, Pages: 1}, []string{"tags"}, []string{"max"}},
+		{"min value", documentMeta{auditMeta: auditMeta{CreatedBy: "1"}, Title: "Readme"}, []string{"pages"}, []string{"min"}},
+		{"pointer", &documentMeta{Title: "Readme", Pages: 1}, []string{"createdBy"}, []string{"required"}},
+		{"every field", documentMeta{}, []string{"createdBy", "title", "pages"}, []string{"required", "required", "min"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateMeta(test.meta)
+			if test.fields == nil {
+				assert.NoError(t, err)
+				return
+			}
+			var validationErr ValidationError
+			if assert.ErrorAs(t, err, &validationErr) {
+				fields := make([]string, 0)
+				rules := make([]string, 0)
+				for _, fieldErr := range validationErr.Errors {
+					fields = append(fields, fieldErr.Field)
+					rules = append(rules, fieldErr.Rule)
+				}
+				assert.Equal(t, test.fields, fields)
+				assert.Equal(t, test.rules, rules)
+			}
+		})
+	}
+}
+
+func TestValidateMetaCallsValidator(t *testing.T) {
+	assert.NoError(t, ValidateMeta(checkedMeta{Name: "docs"}))
+	assert.EqualError(t, ValidateMeta(checkedMeta{Name: "root"}), "name is reserved")
+}
+
+func TestValidateMetaUnknownRule(t *testing.T) {
+	meta := struct {
+		Name string `json:"name" validate:"email"`
+	}{Name: "docs"}
+	var validationErr ValidationError
+	if assert.ErrorAs(t, ValidateMeta(meta), &validationErr) {
+		assert.Equal(t, "email", validationErr.Errors[0].Rule)
+	}
+}
+
+func TestDecodeMetaEmbeddedAndUnknownKeys(t *testing.T) {
+	assert := assert.New(t)
+	meta, unknownMeta, err := DecodeMeta[documentMeta](map[string]interface{}{
+		"createdBy": "1",
+		"title":     "Readme",
+		"pages":     float64(3),
+		"owner":     "team-a",
+		"Secret":    "kept",
+	})
+	assert.NoError(err)
+	assert.Equal(documentMeta{auditMeta: auditMeta{CreatedBy: "1"}, Title: "Readme", Pages: 3}, meta)
+	assert.Equal(map[string]interface{}{"owner": "team-a", "Secret": "kept"}, unknownMeta)
+}
+
+func TestEncodeMetaRoundTripsUnknownKeys(t *testing.T) {
+	assert := assert.New(t)
+	original := map[string]interface{}{
+		"createdBy": "1",
+		"title":     "Readme",
+		"pages":     float64(3),
+		"owner":     "team-a",
+	}
+	meta, unknownMeta, err := DecodeMeta[documentMeta](original)
+	assert.NoError(err)
+
+	meta.Title = "Guide"
+	encoded, err := EncodeMeta(meta, unknownMeta)
+	assert.NoError(err)
+	assert.Equal(map[string]interface{}{
+		"createdBy": "1",
+		"title":     "Guide",
+		"pages":     float64(3),
+		"owner":     "team-a",
+	}, encoded)
+}
+
+func TestEncodeMetaDoesNotRestoreClearedFields(t *testing.T) {
+	assert := assert.New(t)
+	meta := documentMeta{auditMeta: auditMeta{CreatedBy: "1"}, Title: "Readme", Pages: 1}
+	encoded, err := EncodeMeta(meta, map[string]interface{}{"status": "draft", "title": "Old"})
+	assert.NoError(err)
+	assert.NotContains(encoded, "status")
+	assert.Equal("Readme", encoded["title"])
+}
+
+func TestEncodeMetaValidates(t *testing.T) {
+	_, err := EncodeMeta(documentMeta{}, nil)
+	assert.ErrorAs(t, err, &ValidationError{})
+}
+
+func TestDecodeMetaMapKeepsEverything(t *testing.T) {
+	meta, unknownMeta, err := DecodeMeta[map[string]interface{}](map[string]interface{}{"a": "b"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"a": "b"}, meta)
+	assert.Nil(t, unknownMeta)
+}
@@ -1,7 +1,7 @@
 package warrant
 
 import (
-	"log"
+	"log/slog"
 	"net/http"
 )
 
@@ -9,15 +9,28 @@ type GetObjectIdFunc func(r *http.Request) string
 
 type GetUserIdFunc func(r *http.Request) string
 
+type GetDecisionMetadataFunc func(r *http.Request) map[string]string
+
+type OnErrorFunc func(w http.ResponseWriter, r *http.Request, err error)
+
 type NewEnsureIsAuthorizedFunc func(handler http.Handler, options EnsureIsAuthorizedOptions) *EnsureIsAuthorized
 
 type NewEnsureHasPermissionFunc func(handler http.Handler, options EnsureHasPermissionOptions) *EnsureHasPermission
 
 type MiddlewareConfig struct {
-	ApiKey         string
-	GetObjectId    GetObjectIdFunc
-	GetUserId      GetUserIdFunc
-	OnAccessDenied http.HandlerFunc
+	ApiKey              string
+	GetObjectId         GetObjectIdFunc
+	GetUserId           GetUserIdFunc
+	OnAccessDenied      http.HandlerFunc
+	OnUnauthenticated   http.HandlerFunc
+	OnError             OnErrorFunc
+	FailureMode         FailureMode
+	StaleCheckCache     *StaleCheckCache
+	CircuitBreaker      *CircuitBreaker
+	CoalesceChecks      bool
+	DecisionSink        DecisionSink
+	GetDecisionMetadata GetDecisionMetadataFunc
+	Logger              *slog.Logger
 }
 
 type Middleware struct {
@@ -26,10 +39,11 @@ type Middleware struct {
 }
 
 type EnsureIsAuthorizedOptions struct {
-	ObjectType string
-	ObjectId   string
-	Relation   string
-	UserId     string
+	ObjectType  string
+	ObjectId    string
+	Relation    string
+	UserId      string
+	FailureMode FailureMode
 }
 
 type EnsureIsAuthorized struct {
@@ -41,6 +55,7 @@ type EnsureIsAuthorized struct {
 type EnsureHasPermissionOptions struct {
 	PermissionId string
 	UserId       string
+	FailureMode  FailureMode
 }
 
 type EnsureHasPermission struct {
@@ -49,10 +64,78 @@ type EnsureHasPermission struct {
 	options EnsureHasPermissionOptions
 }
 
+func (mw Middleware) requestOptions(r *http.Request) RequestOptions {
+	if mw.config.GetDecisionMetadata == nil {
+		return RequestOptions{}
+	}
+	return RequestOptions{
+		Metadata: mw.config.GetDecisionMetadata(r),
+	}
+}
+
+// authorize runs the check for a request and writes the appropriate response
+// when the handler should not be called.
+func (mw Middleware) authorize(w http.ResponseWriter, r *http.Request, params *WarrantCheckParams) bool {
+	if params.WarrantCheck.Subject.GetObjectId() == "" {
+		mw.config.Logger.LogAttrs(r.Context(), slog.LevelInfo, "warrant request unauthenticated",
+			slog.String("path", r.URL.Path))
+		mw.config.OnUnauthenticated(w, r)
+		return false
+	}
+
+	accessCheckRequest := AccessCheckRequest{
+		RequestOptions: params.RequestOptions,
+		Warrants:       []WarrantCheck{params.WarrantCheck},
+		Debug:          params.Debug,
+	}
+	outcome := mw.client.evaluate(&accessCheckRequest, resolveFailureMode(params.FailureMode, mw.config.FailureMode))
+	attrs := checkLogAttrs(r, params.WarrantCheck)
+	if outcome.err != nil {
+		attrs = append(attrs, slog.String("error", outcome.err.Error()))
+		if outcome.failureMode == "" {
+			mw.config.Logger.LogAttrs(r.Context(), slog.LevelError, "warrant check failed", attrs...)
+			mw.config.OnError(w, r, outcome.err)
+			return false
+		}
+		attrs = append(attrs, slog.String("failureMode", string(outcome.failureMode)))
+		mw.config.Logger.LogAttrs(r.Context(), slog.LevelWarn, "warrant check failed, applied failure mode", attrs...)
+	}
+
+	if !outcome.isAuthorized {
+		mw.config.Logger.LogAttrs(r.Context(), slog.LevelInfo, "warrant access denied", attrs...)
+		mw.config.OnAccessDenied(w, r)
+		return false
+	}
+	return true
+}
+
+func checkLogAttrs(r *http.Request, warrantCheck WarrantCheck) []slog.Attr {
+	return []slog.Attr{
+		slog.String("path", r.URL.Path),
+		slog.String("objectType", warrantCheck.Object.GetObjectType()),
+		slog.String("objectId", warrantCheck.Object.GetObjectId()),
+		slog.String("relation", warrantCheck.Relation),
+		slog.String("subjectType", warrantCheck.Subject.GetObjectType()),
+		slog.String("subjectId", warrantCheck.Subject.GetObjectId()),
+	}
+}
+
 func defaultOnAccessDenied(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusForbidden)
+}
+
+func defaultOnUnauthenticated(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusUnauthorized)
 }
 
+func defaultOnError(w http.ResponseWriter, r *http.Request, err error) {
+	if IsUnavailable(err) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusInternalServerError)
+}
+
 func (eia *EnsureIsAuthorized) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	objectId := eia.options.ObjectId
 	userId := eia.options.UserId
@@ -65,7 +148,8 @@ func (eia *EnsureIsAuthorized) ServeHTTP(w http.ResponseWriter, r *http.Request)
 		userId = eia.mw.config.GetUserId(r)
 	}
 
-	isAuthorized, err := eia.mw.client.Check(&WarrantCheckParams{
+	checkParams := &WarrantCheckParams{
+		RequestOptions: eia.mw.requestOptions(r),
 		WarrantCheck: WarrantCheck{
 			Object: Object{
 				ObjectType: eia.options.ObjectType,
@@ -77,15 +161,9 @@ func (eia *EnsureIsAuthorized) ServeHTTP(w http.ResponseWriter, r *http.Request)
 				ObjectId:   userId,
 			},
 		},
-	})
-	if err != nil {
-		log.Println(err)
-		eia.mw.config.OnAccessDenied(w, r)
-		return
+		FailureMode: eia.options.FailureMode,
 	}
-
-	if !isAuthorized {
-		eia.mw.config.OnAccessDenied(w, r)
+	if !eia.mw.authorize(w, r, checkParams) {
 		return
 	}
 
@@ -114,18 +192,13 @@ func (ehp *EnsureHasPermission) ServeHTTP(w http.ResponseWriter, r *http.Request
 		userId = ehp.mw.config.GetUserId(r)
 	}
 
-	isAuthorized, err := ehp.mw.client.CheckUserHasPermission(&PermissionCheckParams{
-		PermissionId: ehp.options.PermissionId,
-		UserId:       userId,
-	})
-	if err != nil {
-		log.Println(err)
-		ehp.mw.config.OnAccessDenied(w, r)
-		return
+	permissionCheckParams := &PermissionCheckParams{
+		RequestOptions: ehp.mw.requestOptions(r),
+		PermissionId:   ehp.options.PermissionId,
+		UserId:         userId,
+		FailureMode:    ehp.options.FailureMode,
 	}
-
-	if !isAuthorized {
-		ehp.mw.config.OnAccessDenied(w, r)
+	if !ehp.mw.authorize(w, r, permissionCheckParams.warrantCheckParams()) {
 		return
 	}
 
@@ -144,6 +217,15 @@ func NewMiddleware(middlewareConfig MiddlewareConfig) *Middleware {
 	if middlewareConfig.OnAccessDenied == nil {
 		middlewareConfig.OnAccessDenied = defaultOnAccessDenied
 	}
+	if middlewareConfig.OnUnauthenticated == nil {
+		middlewareConfig.OnUnauthenticated = defaultOnUnauthenticated
+	}
+	if middlewareConfig.OnError == nil {
+		middlewareConfig.OnError = defaultOnError
+	}
+	if middlewareConfig.Logger == nil {
+		middlewareConfig.Logger = slog.Default()
+	}
 
 	return &Middleware{
 		config: middlewareConfig,
@@ -152,6 +234,12 @@ func NewMiddleware(middlewareConfig MiddlewareConfig) *Middleware {
 			ApiEndpoint:             ApiEndpoint,
 			AuthorizeEndpoint:       AuthorizeEndpoint,
 			SelfServiceDashEndpoint: SelfServiceDashEndpoint,
+			DecisionSink:            middlewareConfig.DecisionSink,
+			FailureMode:             middlewareConfig.FailureMode,
+			StaleCheckCache:         middlewareConfig.StaleCheckCache,
+			CheckCircuitBreaker:     middlewareConfig.CircuitBreaker,
+			CoalesceChecks:          middlewareConfig.CoalesceChecks,
+			Logger:                  middlewareConfig.Logger,
 		}),
 	}
 }
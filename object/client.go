@@ -1,9 +1,12 @@
 package object
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net/http"
 
 	"github.com/google/go-querystring/query"
 	"github.com/warrant-dev/warrant-go/v6"
@@ -20,7 +23,28 @@ func NewClient(config warrant.ClientConfig) Client {
 }
 
 func (c Client) Create(params *warrant.ObjectParams) (*warrant.Object, error) {
-	resp, err := c.apiClient.MakeRequest("POST", "/v2/objects", params, &warrant.RequestOptions{})
+	if params == nil {
+		params = &warrant.ObjectParams{}
+	}
+	createdObject, err := c.create(params)
+	if errors.Is(err, warrant.ErrDuplicateOnRetry) {
+		return c.findObject(params)
+	}
+	return createdObject, err
+}
+
+func Create(params *warrant.ObjectParams) (*warrant.Object, error) {
+	return getClient().Create(params)
+}
+
+// create creates an object without resolving ErrDuplicateOnRetry, so that
+// callers can tell a record they created from one that already existed.
+func (c Client) create(params *warrant.ObjectParams) (*warrant.Object, error) {
+	err := c.apiClient.Config.MetaSchemas.Validate(params.ObjectType, params.Meta)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.apiClient.MakeRequest("POST", "/v2/objects", params, &params.RequestOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -34,15 +58,36 @@ func (c Client) Create(params *warrant.ObjectParams) (*warrant.Object, error) {
 	if err != nil {
 		return nil, warrant.WrapError("Invalid response from server", err)
 	}
+	newObject.WarrantToken = resp.Header.Get("Warrant-Token")
 	return &newObject, nil
 }
 
-func Create(params *warrant.ObjectParams) (*warrant.Object, error) {
-	return getClient().Create(params)
+func (c Client) BatchCreate(params []warrant.ObjectParams) ([]warrant.Object, error) {
+	createdObjects, err := c.batchCreate(params)
+	if errors.Is(err, warrant.ErrDuplicateOnRetry) {
+		existingObjects := make([]warrant.Object, 0, len(params))
+		for i := range params {
+			existingObject, err := c.findObject(&params[i])
+			if err != nil {
+				return nil, err
+			}
+			existingObjects = append(existingObjects, *existingObject)
+		}
+		return existingObjects, nil
+	}
+	return createdObjects, err
 }
 
-func (c Client) BatchCreate(params []warrant.ObjectParams) ([]warrant.Object, error) {
-	resp, err := c.apiClient.MakeRequest("POST", "/v2/objects", params, &warrant.RequestOptions{})
+func BatchCreate(params []warrant.ObjectParams) ([]warrant.Object, error) {
+	return getClient().BatchCreate(params)
+}
+
+func (c Client) batchCreate(params []warrant.ObjectParams) ([]warrant.Object, error) {
+	err := c.apiClient.Config.MetaSchemas.ValidateObjects(params)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.apiClient.MakeRequest("POST", "/v2/objects", params, batchRequestOptions(params))
 	if err != nil {
 		return nil, err
 	}
@@ -56,13 +101,13 @@ func (c Client) BatchCreate(params []warrant.ObjectParams) ([]warrant.Object, er
 	if err != nil {
 		return nil, warrant.WrapError("Invalid response from server", err)
 	}
+	warrantToken := resp.Header.Get("Warrant-Token")
+	for i := range newObjects {
+		newObjects[i].WarrantToken = warrantToken
+	}
 	return newObjects, nil
 }
 
-func BatchCreate(params []warrant.ObjectParams) ([]warrant.Object, error) {
-	return getClient().BatchCreate(params)
-}
-
 func (c Client) Get(objectType string, objectId string, params *warrant.ObjectParams) (*warrant.Object, error) {
 	if params == nil {
 		params = &warrant.ObjectParams{}
@@ -89,7 +134,14 @@ func Get(objectType string, objectId string, params *warrant.ObjectParams) (*war
 }
 
 func (c Client) Update(objectType string, objectId string, params *warrant.ObjectParams) (*warrant.Object, error) {
-	resp, err := c.apiClient.MakeRequest("PUT", fmt.Sprintf("/v2/objects/%s/%s", objectType, objectId), params, &warrant.RequestOptions{})
+	if params == nil {
+		params = &warrant.ObjectParams{}
+	}
+	err := c.apiClient.Config.MetaSchemas.Validate(objectType, params.Meta)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.apiClient.MakeRequest("PUT", fmt.Sprintf("/v2/objects/%s/%s", objectType, objectId), params, &params.RequestOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -103,6 +155,7 @@ func (c Client) Update(objectType string, objectId string, params *warrant.Objec
 	if err != nil {
 		return nil, warrant.WrapError("Invalid response from server", err)
 	}
+	updatedObject.WarrantToken = resp.Header.Get("Warrant-Token")
 	return &updatedObject, nil
 }
 
@@ -110,6 +163,173 @@ func Update(objectType string, objectId string, params *warrant.ObjectParams) (*
 	return getClient().Update(objectType, objectId, params)
 }
 
+// Upsert creates the object if it does not exist and otherwise updates its
+// meta if it differs. A nil Meta leaves an existing object's meta unchanged.
+func (c Client) Upsert(params *warrant.ObjectParams) (*warrant.UpsertResult[warrant.Object], error) {
+	if params == nil || params.ObjectId == "" {
+		return nil, warrant.Error{Message: "ObjectId is required to upsert an object"}
+	}
+	existingObject, err := c.findObject(params)
+	if isStatus(err, http.StatusNotFound) {
+		createdObject, err := c.create(params)
+		if isStatus(err, http.StatusConflict) {
+			// Created concurrently since the lookup. A retried create that
+			// may have created it itself ends up here too, since it cannot
+			// tell its own record from one that already existed.
+			existingObject, err = c.findObject(params)
+			if err != nil {
+				return nil, err
+			}
+			return c.updateIfChanged(params, existingObject)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &warrant.UpsertResult[warrant.Object]{
+			Record: *createdObject,
+			Action: warrant.UpsertCreated,
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return c.updateIfChanged(params, existingObject)
+}
+
+func Upsert(params *warrant.ObjectParams) (*warrant.UpsertResult[warrant.Object], error) {
+	return getClient().Upsert(params)
+}
+
+// BatchUpsert looks up each object, creates the missing ones in a single batch
+// request and updates those whose meta differs, as Upsert does.
+func (c Client) BatchUpsert(params []warrant.ObjectParams) ([]warrant.UpsertResult[warrant.Object], error) {
+	results := make([]warrant.UpsertResult[warrant.Object], len(params))
+	objectsToCreate := make([]warrant.ObjectParams, 0)
+	createIndexes := make([]int, 0)
+	for i := range params {
+		if params[i].ObjectId == "" {
+			return nil, warrant.Error{Message: "ObjectId is required to upsert an object"}
+		}
+		existingObject, err := c.findObject(&params[i])
+		if isStatus(err, http.StatusNotFound) {
+			objectsToCreate = append(objectsToCreate, params[i])
+			createIndexes = append(createIndexes, i)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		result, err := c.updateIfChanged(&params[i], existingObject)
+		if err != nil {
+			return nil, err
+		}
+		results[i] = *result
+	}
+	if len(objectsToCreate) == 0 {
+		return results, nil
+	}
+
+	createdObjects, err := c.batchCreate(objectsToCreate)
+	if isStatus(err, http.StatusConflict) {
+		// Some objects were created concurrently since the lookup.
+		for _, i := range createIndexes {
+			result, err := c.Upsert(&params[i])
+			if err != nil {
+				return nil, err
+			}
+			results[i] = *result
+		}
+		return results, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for j, i := range createIndexes {
+		if j < len(createdObjects) {
+			results[i].Record = createdObjects[j]
+		}
+		results[i].Action = warrant.UpsertCreated
+	}
+	return results, nil
+}
+
+func BatchUpsert(params []warrant.ObjectParams) ([]warrant.UpsertResult[warrant.Object], error) {
+	return getClient().BatchUpsert(params)
+}
+
+// UpsertAs runs Upsert and converts the resulting object with toRecord. It
+// lets the user, tenant, role and other clients upsert their own record types.
+func UpsertAs[T any](c Client, params *warrant.ObjectParams, toRecord func(warrant.Object) T) (*warrant.UpsertResult[T], error) {
+	result, err := c.Upsert(params)
+	if err != nil {
+		return nil, err
+	}
+	return &warrant.UpsertResult[T]{
+		Record: toRecord(result.Record),
+		Action: result.Action,
+	}, nil
+}
+
+// BatchUpsertAs runs BatchUpsert and converts the resulting objects with
+// toRecord.
+func BatchUpsertAs[T any](c Client, params []warrant.ObjectParams, toRecord func(warrant.Object) T) ([]warrant.UpsertResult[T], error) {
+	objectResults, err := c.BatchUpsert(params)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]warrant.UpsertResult[T], 0, len(objectResults))
+	for _, objectResult := range objectResults {
+		results = append(results, warrant.UpsertResult[T]{
+			Record: toRecord(objectResult.Record),
+			Action: objectResult.Action,
+		})
+	}
+	return results, nil
+}
+
+func (c Client) updateIfChanged(params *warrant.ObjectParams, existingObject *warrant.Object) (*warrant.UpsertResult[warrant.Object], error) {
+	if params.Meta == nil || MetaEqual(params.Meta, existingObject.Meta) {
+		return &warrant.UpsertResult[warrant.Object]{
+			Record: *existingObject,
+			Action: warrant.UpsertUnchanged,
+		}, nil
+	}
+	updatedObject, err := c.Update(params.ObjectType, params.ObjectId, params)
+	if err != nil {
+		return nil, err
+	}
+	return &warrant.UpsertResult[warrant.Object]{
+		Record: *updatedObject,
+		Action: warrant.UpsertUpdated,
+	}, nil
+}
+
+// MetaEqual compares meta by its JSON encoding, so that numbers compare equal
+// regardless of their Go type and nil equals empty.
+func MetaEqual(a map[string]interface{}, b map[string]interface{}) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	return jsonEqual(a, b)
+}
+
+func jsonEqual(a interface{}, b interface{}) bool {
+	aJson, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	bJson, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(aJson, bJson)
+}
+
+func isStatus(err error, statusCode int) bool {
+	var warrantErr warrant.Error
+	return errors.As(err, &warrantErr) && warrantErr.StatusCode == statusCode
+}
+
 func (c Client) Delete(objectType string, objectId string) (string, error) {
 	resp, err := c.apiClient.MakeRequest("DELETE", fmt.Sprintf("/v2/objects/%s/%s", objectType, objectId), nil, &warrant.RequestOptions{})
 	if err != nil {
@@ -125,7 +345,7 @@ func Delete(objectType string, objectId string) (string, error) {
 }
 
 func (c Client) BatchDelete(params []warrant.ObjectParams) (string, error) {
-	resp, err := c.apiClient.MakeRequest("DELETE", "/v2/objects", params, &warrant.RequestOptions{})
+	resp, err := c.apiClient.MakeRequest("DELETE", "/v2/objects", params, batchRequestOptions(params))
 	if err != nil {
 		return "", err
 	}
@@ -138,6 +358,180 @@ func BatchDelete(params []warrant.ObjectParams) (string, error) {
 	return getClient().BatchDelete(params)
 }
 
+type DeleteCascadeOptions struct {
+	// Report what would be deleted without deleting anything.
+	DryRun bool
+	// The most warrants deleted per batch request. Defaults to 100.
+	BatchSize int
+}
+
+type DeleteCascadeReport struct {
+	// Every warrant referencing the object, as the object or as the subject.
+	Warrants        []warrant.Warrant
+	ObjectWarrants  int
+	SubjectWarrants int
+	DeletedWarrants int
+	ObjectDeleted   bool
+	DryRun          bool
+	WarrantToken    string
+}
+
+// DeleteCascade deletes every warrant in which the object is the object or the
+// subject, in batches, and then the object itself. With DryRun set it only
+// lists the warrants. If a request fails, the returned report shows how far
+// the delete got.
+func (c Client) DeleteCascade(objectType string, objectId string, options *DeleteCascadeOptions) (*DeleteCascadeReport, error) {
+	if options == nil {
+		options = &DeleteCascadeOptions{}
+	}
+	report := &DeleteCascadeReport{
+		DryRun: options.DryRun,
+	}
+
+	warrantClient := warrant.NewClient(c.apiClient.Config)
+	seen := make(map[warrant.Warrant]bool)
+	objectWarrants, err := warrantClient.ListAll(&warrant.ListWarrantParams{ObjectType: objectType, ObjectId: objectId})
+	if err != nil {
+		return report, err
+	}
+	subjectWarrants, err := warrantClient.ListAll(&warrant.ListWarrantParams{SubjectType: objectType, SubjectId: objectId})
+	if err != nil {
+		return report, err
+	}
+	report.ObjectWarrants = len(objectWarrants)
+	report.SubjectWarrants = len(subjectWarrants)
+	for _, foundWarrant := range append(objectWarrants, subjectWarrants...) {
+		if seen[foundWarrant] {
+			continue
+		}
+		seen[foundWarrant] = true
+		report.Warrants = append(report.Warrants, foundWarrant)
+	}
+	if options.DryRun {
+		return report, nil
+	}
+
+	report.DeletedWarrants, report.WarrantToken, err = warrantClient.DeleteAll(report.Warrants, options.BatchSize)
+	if err != nil {
+		return report, err
+	}
+
+	warrantToken, err := c.Delete(objectType, objectId)
+	if err != nil {
+		return report, err
+	}
+	report.ObjectDeleted = true
+	report.WarrantToken = warrantToken
+	return report, nil
+}
+
+func DeleteCascade(objectType string, objectId string, options *DeleteCascadeOptions) (*DeleteCascadeReport, error) {
+	return getClient().DeleteCascade(objectType, objectId, options)
+}
+
+var ErrPreconditionFailed = warrant.Error{
+	Message:    "Object meta does not match the expected values",
+	StatusCode: http.StatusPreconditionFailed,
+}
+
+var ErrPatchConflict = warrant.Error{
+	Message:    "Object meta kept changing while being patched",
+	StatusCode: http.StatusConflict,
+}
+
+type PatchMetaOptions struct {
+	// Meta values the object must have for the patch to be applied, such as
+	// a version number. A nil value requires the key to be absent.
+	Expected map[string]interface{}
+	// The most times the patched meta is written before giving up. Defaults
+	// to 3.
+	MaxAttempts int
+}
+
+// PatchMeta applies an RFC 7396 JSON merge patch to an object's meta, leaving
+// keys not in the patch untouched. The Warrant API replaces meta as a whole,
+// so the object is read, patched and written back, then read again to check
+// that no other writer overwrote the patch in between. If one did, the cycle
+// is repeated. This narrows but cannot fully close the window for lost
+// updates.
+func (c Client) PatchMeta(objectType string, objectId string, patch map[string]interface{}, options *PatchMetaOptions) (*warrant.Object, error) {
+	if options == nil {
+		options = &PatchMetaOptions{}
+	}
+	maxAttempts := options.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 3
+	}
+	normalizedPatch, err := normalizeMeta(patch)
+	if err != nil {
+		return nil, err
+	}
+	expectedMeta, err := normalizeMeta(options.Expected)
+	if err != nil {
+		return nil, err
+	}
+
+	objectParams := &warrant.ObjectParams{
+		ObjectType: objectType,
+		ObjectId:   objectId,
+	}
+	for attempt := 0; ; attempt++ {
+		currentObject, err := c.findObject(objectParams)
+		if err != nil {
+			return nil, err
+		}
+		patchedMeta := warrant.MergePatch(currentObject.Meta, normalizedPatch)
+		// After a write, meta that already matches the patch is our own
+		// write landing, and the patch may itself have changed the expected
+		// keys. Before any write it must still meet Expected.
+		patched := MetaEqual(patchedMeta, currentObject.Meta)
+		if patched && attempt > 0 {
+			return currentObject, nil
+		}
+		for key, expectedValue := range expectedMeta {
+			if !jsonEqual(currentObject.Meta[key], expectedValue) {
+				return nil, ErrPreconditionFailed
+			}
+		}
+		if patched {
+			return currentObject, nil
+		}
+		if attempt >= maxAttempts {
+			return nil, ErrPatchConflict
+		}
+		_, err = c.Update(objectType, objectId, &warrant.ObjectParams{
+			ObjectType: objectType,
+			ObjectId:   objectId,
+			Meta:       patchedMeta,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func PatchMeta(objectType string, objectId string, patch map[string]interface{}, options *PatchMetaOptions) (*warrant.Object, error) {
+	return getClient().PatchMeta(objectType, objectId, patch, options)
+}
+
+// normalizeMeta round-trips meta through JSON so that it can be compared and
+// merged with meta read from the Warrant API.
+func normalizeMeta(meta map[string]interface{}) (map[string]interface{}, error) {
+	if meta == nil {
+		return nil, nil
+	}
+	metaJson, err := json.Marshal(meta)
+	if err != nil {
+		return nil, warrant.WrapError("Invalid object meta", err)
+	}
+	var normalized map[string]interface{}
+	err = json.Unmarshal(metaJson, &normalized)
+	if err != nil {
+		return nil, warrant.WrapError("Invalid object meta", err)
+	}
+	return normalized, nil
+}
+
 func (c Client) ListObjects(listParams *warrant.ListObjectParams) (warrant.ListResponse[warrant.Object], error) {
 	if listParams == nil {
 		listParams = &warrant.ListObjectParams{}
@@ -168,6 +562,160 @@ func ListObjects(listParams *warrant.ListObjectParams) (warrant.ListResponse[war
 	return getClient().ListObjects(listParams)
 }
 
+// TypedClient decodes object meta into M on reads and validates and encodes
+// it on writes.
+type TypedClient[M any] struct {
+	client Client
+}
+
+func Typed[M any](c Client) TypedClient[M] {
+	return TypedClient[M]{
+		client: c,
+	}
+}
+
+func (c TypedClient[M]) Get(objectType string, objectId string, params *warrant.ObjectParams) (*warrant.ObjectOf[M], error) {
+	foundObject, err := c.client.Get(objectType, objectId, params)
+	if err != nil {
+		return nil, err
+	}
+	return warrant.DecodeObject[M](*foundObject)
+}
+
+func GetAs[M any](objectType string, objectId string, params *warrant.ObjectParams) (*warrant.ObjectOf[M], error) {
+	return Typed[M](getClient()).Get(objectType, objectId, params)
+}
+
+func (c TypedClient[M]) List(listParams *warrant.ListObjectParams) (warrant.ListResponse[warrant.ObjectOf[M]], error) {
+	var typedListResponse warrant.ListResponse[warrant.ObjectOf[M]]
+	objectsListResponse, err := c.client.ListObjects(listParams)
+	if err != nil {
+		return typedListResponse, err
+	}
+	typedListResponse, err = DecodeList[M](objectsListResponse)
+	if err != nil {
+		return typedListResponse, err
+	}
+	return typedListResponse, nil
+}
+
+func ListAs[M any](listParams *warrant.ListObjectParams) (warrant.ListResponse[warrant.ObjectOf[M]], error) {
+	return Typed[M](getClient()).List(listParams)
+}
+
+func (c TypedClient[M]) Create(object *warrant.ObjectOf[M]) (*warrant.ObjectOf[M], error) {
+	params, err := object.Params()
+	if err != nil {
+		return nil, err
+	}
+	createdObject, err := c.client.Create(params)
+	if err != nil {
+		return nil, err
+	}
+	return warrant.DecodeObject[M](*createdObject)
+}
+
+func CreateAs[M any](object *warrant.ObjectOf[M]) (*warrant.ObjectOf[M], error) {
+	return Typed[M](getClient()).Create(object)
+}
+
+// Update replaces the object's meta with object.Meta, keeping any keys in
+// object.UnknownMeta that M does not declare.
+func (c TypedClient[M]) Update(object *warrant.ObjectOf[M]) (*warrant.ObjectOf[M], error) {
+	params, err := object.Params()
+	if err != nil {
+		return nil, err
+	}
+	updatedObject, err := c.client.Update(object.ObjectType, object.ObjectId, params)
+	if err != nil {
+		return nil, err
+	}
+	return warrant.DecodeObject[M](*updatedObject)
+}
+
+func UpdateAs[M any](object *warrant.ObjectOf[M]) (*warrant.ObjectOf[M], error) {
+	return Typed[M](getClient()).Update(object)
+}
+
+// TypeClient is a TypedClient bound to a single object type. The user,
+// tenant, role, permission, feature and pricing tier clients wrap it for their
+// own Typed clients.
+type TypeClient[M any] struct {
+	typed      TypedClient[M]
+	objectType string
+}
+
+func TypedFor[M any](c Client, objectType string) TypeClient[M] {
+	return TypeClient[M]{
+		typed:      Typed[M](c),
+		objectType: objectType,
+	}
+}
+
+func (c TypeClient[M]) Get(objectId string, options warrant.RequestOptions) (*warrant.ObjectOf[M], error) {
+	return c.typed.Get(c.objectType, objectId, &warrant.ObjectParams{
+		RequestOptions: options,
+	})
+}
+
+func (c TypeClient[M]) List(listParams warrant.ListParams) (warrant.ListResponse[warrant.ObjectOf[M]], error) {
+	return c.typed.List(&warrant.ListObjectParams{
+		ListParams: listParams,
+		ObjectType: c.objectType,
+	})
+}
+
+// Create creates typedObject as an object of the client's type, whatever its
+// ObjectType says.
+func (c TypeClient[M]) Create(typedObject *warrant.ObjectOf[M]) (*warrant.ObjectOf[M], error) {
+	boundObject := *typedObject
+	boundObject.ObjectType = c.objectType
+	return c.typed.Create(&boundObject)
+}
+
+func (c TypeClient[M]) Update(typedObject *warrant.ObjectOf[M]) (*warrant.ObjectOf[M], error) {
+	boundObject := *typedObject
+	boundObject.ObjectType = c.objectType
+	return c.typed.Update(&boundObject)
+}
+
+func DecodeList[M any](objectsListResponse warrant.ListResponse[warrant.Object]) (warrant.ListResponse[warrant.ObjectOf[M]], error) {
+	typedListResponse := warrant.ListResponse[warrant.ObjectOf[M]]{
+		Results:    make([]warrant.ObjectOf[M], 0, len(objectsListResponse.Results)),
+		PrevCursor: objectsListResponse.PrevCursor,
+		NextCursor: objectsListResponse.NextCursor,
+	}
+	for _, object := range objectsListResponse.Results {
+		typedObject, err := warrant.DecodeObject[M](object)
+		if err != nil {
+			return typedListResponse, err
+		}
+		typedListResponse.Results = append(typedListResponse.Results, *typedObject)
+	}
+	return typedListResponse, nil
+}
+
+// findObject fetches the object matching params. It is used when a retried
+// create finds that an earlier attempt already created the object.
+func (c Client) findObject(params *warrant.ObjectParams) (*warrant.Object, error) {
+	if params.ObjectId == "" {
+		return nil, warrant.WrapError("Object created with a server-generated id not found", warrant.ErrDuplicateOnRetry)
+	}
+	getParams := &warrant.ObjectParams{}
+	getParams.SetWarrantToken("latest")
+	getParams.SetContext(params.RequestContext)
+	return c.Get(params.ObjectType, params.ObjectId, getParams)
+}
+
+// Batch requests are a single API call, so the request options of the first
+// item apply to the whole batch.
+func batchRequestOptions(params []warrant.ObjectParams) *warrant.RequestOptions {
+	if len(params) == 0 {
+		return &warrant.RequestOptions{}
+	}
+	return &params[0].RequestOptions
+}
+
 func getClient() Client {
 	config := warrant.ClientConfig{
 		ApiKey:                  warrant.ApiKey,
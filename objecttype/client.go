@@ -2,8 +2,10 @@ package objecttype
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net/http"
 
 	"github.com/google/go-querystring/query"
 	"github.com/warrant-dev/warrant-go/v6"
@@ -118,6 +120,34 @@ func BatchUpdate(params []warrant.ObjectTypeParams) ([]warrant.ObjectType, error
 	return getClient().BatchUpdate(params)
 }
 
+// Bootstrap creates each object type that doesn't exist yet and returns the
+// ones it created. Existing object types are left as they are.
+func (c Client) Bootstrap(params []warrant.ObjectTypeParams) ([]warrant.ObjectType, error) {
+	createdObjectTypes := make([]warrant.ObjectType, 0)
+	for i := range params {
+		getParams := &warrant.ObjectTypeParams{}
+		getParams.SetWarrantToken("latest")
+		_, err := c.Get(params[i].Type, getParams)
+		if err == nil {
+			continue
+		}
+		var warrantErr warrant.Error
+		if !errors.As(err, &warrantErr) || warrantErr.StatusCode != http.StatusNotFound {
+			return createdObjectTypes, err
+		}
+		createdObjectType, err := c.Create(&params[i])
+		if err != nil {
+			return createdObjectTypes, err
+		}
+		createdObjectTypes = append(createdObjectTypes, *createdObjectType)
+	}
+	return createdObjectTypes, nil
+}
+
+func Bootstrap(params []warrant.ObjectTypeParams) ([]warrant.ObjectType, error) {
+	return getClient().Bootstrap(params)
+}
+
 func (c Client) Delete(objectTypeId string) (string, error) {
 	resp, err := c.apiClient.MakeRequest("DELETE", fmt.Sprintf("/v2/object-types/%s", objectTypeId), nil, &warrant.RequestOptions{})
 	if err != nil {
@@ -1,9 +1,36 @@
 package warrant
 
+import "context"
+
 type RequestOptions struct {
-	WarrantToken string `json:"warrantToken,omitempty" url:"warrantToken,omitempty"`
+	WarrantToken   string            `json:"warrantToken,omitempty" url:"warrantToken,omitempty"`
+	Metadata       map[string]string `json:"-" url:"-"`
+	RequestContext context.Context   `json:"-" url:"-"`
+	IdempotencyKey string            `json:"-" url:"-"`
 }
 
 func (requestOptions *RequestOptions) SetWarrantToken(token string) {
 	requestOptions.WarrantToken = token
 }
+
+func (requestOptions *RequestOptions) SetMetadata(key string, value string) {
+	if requestOptions.Metadata == nil {
+		requestOptions.Metadata = make(map[string]string)
+	}
+	requestOptions.Metadata[key] = value
+}
+
+func (requestOptions *RequestOptions) SetContext(ctx context.Context) {
+	requestOptions.RequestContext = ctx
+}
+
+func (requestOptions *RequestOptions) SetIdempotencyKey(key string) {
+	requestOptions.IdempotencyKey = key
+}
+
+func (requestOptions *RequestOptions) context() context.Context {
+	if requestOptions == nil || requestOptions.RequestContext == nil {
+		return context.Background()
+	}
+	return requestOptions.RequestContext
+}
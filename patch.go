@@ -0,0 +1,24 @@
+package warrant
+
+// MergePatch applies an RFC 7396 JSON merge patch to target and returns the
+// result without modifying target. Keys set to nil in patch are removed,
+// nested objects are merged and all other values replace those in target.
+func MergePatch(target map[string]interface{}, patch map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(target)+len(patch))
+	for key, value := range target {
+		result[key] = value
+	}
+	for key, value := range patch {
+		if value == nil {
+			delete(result, key)
+			continue
+		}
+		if patchObject, ok := value.(map[string]interface{}); ok {
+			targetObject, _ := result[key].(map[string]interface{})
+			result[key] = MergePatch(targetObject, patchObject)
+			continue
+		}
+		result[key] = value
+	}
+	return result
+}
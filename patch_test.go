@@ -0,0 +1,50 @@
+package warrant
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergePatch(t *testing.T) {
+	// The examples from RFC 7396, Appendix A, that have an object target and
+	// patch.
+	tests := []struct {
+		target string
+		patch  string
+		result string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, test := range tests {
+		t.Run(test.target+" "+test.patch, func(t *testing.T) {
+			target := decodeJsonObject(t, test.target)
+			result := MergePatch(target, decodeJsonObject(t, test.patch))
+			assert.Equal(t, decodeJsonObject(t, test.result), result)
+			assert.Equal(t, decodeJsonObject(t, test.target), target)
+		})
+	}
+}
+
+func TestMergePatchDoesNotModifyNestedTarget(t *testing.T) {
+	target := map[string]interface{}{"a": map[string]interface{}{"b": "c"}}
+	result := MergePatch(target, map[string]interface{}{"a": map[string]interface{}{"b": nil}})
+	assert.Equal(t, map[string]interface{}{"a": map[string]interface{}{}}, result)
+	assert.Equal(t, map[string]interface{}{"a": map[string]interface{}{"b": "c"}}, target)
+}
+
+func decodeJsonObject(t *testing.T, value string) map[string]interface{} {
+	var decoded map[string]interface{}
+	assert.NoError(t, json.Unmarshal([]byte(value), &decoded))
+	return decoded
+}
@@ -2,6 +2,7 @@ package permission
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/warrant-dev/warrant-go/v6"
 	"github.com/warrant-dev/warrant-go/v6/object"
@@ -9,11 +10,15 @@ import (
 
 type Client struct {
 	apiClient *warrant.ApiClient
+	// Shared by the client's checks, so that concurrent identical checks
+	// are coalesced when the config enables CoalesceChecks.
+	warrantClient warrant.WarrantClient
 }
 
 func NewClient(config warrant.ClientConfig) Client {
 	return Client{
-		apiClient: warrant.NewApiClient(config),
+		apiClient:     warrant.NewApiClient(config),
+		warrantClient: warrant.NewClient(config),
 	}
 }
 
@@ -31,7 +36,7 @@ func (c Client) Create(params *warrant.PermissionParams) (*warrant.Permission, e
 	if params.Meta != nil {
 		objectParams.Meta = params.Meta
 	}
-	object, err := object.Create(&objectParams)
+	object, err := object.NewClient(c.apiClient.Config).Create(&objectParams)
 	if err != nil {
 		return nil, err
 	}
@@ -55,7 +60,7 @@ func (c Client) Get(permissionId string, params *warrant.PermissionParams) (*war
 		RequestOptions: params.RequestOptions,
 		Meta:           params.Meta,
 	}
-	object, err := object.Get(warrant.ObjectTypePermission, permissionId, &objectParams)
+	object, err := object.NewClient(c.apiClient.Config).Get(warrant.ObjectTypePermission, permissionId, &objectParams)
 	if err != nil {
 		return nil, err
 	}
@@ -79,7 +84,7 @@ func (c Client) Update(permissionId string, params *warrant.PermissionParams) (*
 		RequestOptions: params.RequestOptions,
 		Meta:           params.Meta,
 	}
-	object, err := object.Update(warrant.ObjectTypePermission, permissionId, &objectParams)
+	object, err := object.NewClient(c.apiClient.Config).Update(warrant.ObjectTypePermission, permissionId, &objectParams)
 	if err != nil {
 		return nil, err
 	}
@@ -93,8 +98,48 @@ func Update(permissionId string, params *warrant.PermissionParams) (*warrant.Per
 	return getClient().Update(permissionId, params)
 }
 
+func (c Client) Upsert(params *warrant.PermissionParams) (*warrant.UpsertResult[warrant.Permission], error) {
+	if params == nil {
+		params = &warrant.PermissionParams{}
+	}
+	return object.UpsertAs(object.NewClient(c.apiClient.Config), &warrant.ObjectParams{
+		RequestOptions: params.RequestOptions,
+		ObjectType:     warrant.ObjectTypePermission,
+		ObjectId:       params.PermissionId,
+		Meta:           params.Meta,
+	}, permissionFromObject)
+}
+
+func Upsert(params *warrant.PermissionParams) (*warrant.UpsertResult[warrant.Permission], error) {
+	return getClient().Upsert(params)
+}
+
+func (c Client) BatchUpsert(params []warrant.PermissionParams) ([]warrant.UpsertResult[warrant.Permission], error) {
+	objectsToUpsert := make([]warrant.ObjectParams, 0)
+	for _, permissionParam := range params {
+		objectsToUpsert = append(objectsToUpsert, warrant.ObjectParams{
+			RequestOptions: permissionParam.RequestOptions,
+			ObjectType:     warrant.ObjectTypePermission,
+			ObjectId:       permissionParam.PermissionId,
+			Meta:           permissionParam.Meta,
+		})
+	}
+	return object.BatchUpsertAs(object.NewClient(c.apiClient.Config), objectsToUpsert, permissionFromObject)
+}
+
+func BatchUpsert(params []warrant.PermissionParams) ([]warrant.UpsertResult[warrant.Permission], error) {
+	return getClient().BatchUpsert(params)
+}
+
+func permissionFromObject(permissionObject warrant.Object) warrant.Permission {
+	return warrant.Permission{
+		PermissionId: permissionObject.ObjectId,
+		Meta:         permissionObject.Meta,
+	}
+}
+
 func (c Client) Delete(permissionId string) (string, error) {
-	return object.Delete(warrant.ObjectTypePermission, permissionId)
+	return object.NewClient(c.apiClient.Config).Delete(warrant.ObjectTypePermission, permissionId)
 }
 
 func Delete(permissionId string) (string, error) {
@@ -107,7 +152,7 @@ func (c Client) ListPermissions(listParams *warrant.ListPermissionParams) (warra
 	}
 	var permissionsListResponse warrant.ListResponse[warrant.Permission]
 
-	objectsListResponse, err := object.ListObjects(&warrant.ListObjectParams{
+	objectsListResponse, err := object.NewClient(c.apiClient.Config).ListObjects(&warrant.ListObjectParams{
 		ListParams: listParams.ListParams,
 		ObjectType: warrant.ObjectTypePermission,
 	})
@@ -142,7 +187,7 @@ func (c Client) ListPermissionsForRole(roleId string, listParams *warrant.ListPe
 	}
 	var permissionsListResponse warrant.ListResponse[warrant.Permission]
 
-	queryResponse, err := warrant.Query(fmt.Sprintf("select permission where role:%s is *", roleId), &warrant.QueryParams{
+	queryResponse, err := warrant.NewClient(c.apiClient.Config).Query(fmt.Sprintf("select permission where role:%s is *", roleId), &warrant.QueryParams{
 		ListParams: listParams.ListParams,
 	})
 	if err != nil {
@@ -208,7 +253,7 @@ func (c Client) ListPermissionsForUser(userId string, listParams *warrant.ListPe
 	}
 	var permissionsListResponse warrant.ListResponse[warrant.Permission]
 
-	queryResponse, err := warrant.Query(fmt.Sprintf("select permission where user:%s is *", userId), &warrant.QueryParams{
+	queryResponse, err := warrant.NewClient(c.apiClient.Config).Query(fmt.Sprintf("select permission where user:%s is *", userId), &warrant.QueryParams{
 		ListParams: listParams.ListParams,
 	})
 	if err != nil {
@@ -268,6 +313,341 @@ func RemovePermissionFromUser(permissionId string, userId string) (string, error
 	return getClient().RemovePermissionFromUser(permissionId, userId)
 }
 
+// AssignPermissionToSubject assigns a permission to any subject, including a
+// userset such as warrant.Userset("tenant", "acme", "admin").
+func (c Client) AssignPermissionToSubject(permissionId string, subject warrant.Subject) (*warrant.Warrant, error) {
+	return warrant.NewClient(c.apiClient.Config).Create(&warrant.WarrantParams{
+		ObjectType: warrant.ObjectTypePermission,
+		ObjectId:   permissionId,
+		Relation:   "member",
+		Subject:    subject,
+	})
+}
+
+func AssignPermissionToSubject(permissionId string, subject warrant.Subject) (*warrant.Warrant, error) {
+	return getClient().AssignPermissionToSubject(permissionId, subject)
+}
+
+func (c Client) RemovePermissionFromSubject(permissionId string, subject warrant.Subject) (string, error) {
+	return warrant.NewClient(c.apiClient.Config).Delete(&warrant.WarrantParams{
+		ObjectType: warrant.ObjectTypePermission,
+		ObjectId:   permissionId,
+		Relation:   "member",
+		Subject:    subject,
+	})
+}
+
+func RemovePermissionFromSubject(permissionId string, subject warrant.Subject) (string, error) {
+	return getClient().RemovePermissionFromSubject(permissionId, subject)
+}
+
+func (c Client) ListUsersetsForPermission(permissionId string, listParams *warrant.ListWarrantParams) (warrant.ListResponse[warrant.Subject], error) {
+	return warrant.NewClient(c.apiClient.Config).ListUsersets(warrant.ObjectTypePermission, permissionId, "member", listParams)
+}
+
+func ListUsersetsForPermission(permissionId string, listParams *warrant.ListWarrantParams) (warrant.ListResponse[warrant.Subject], error) {
+	return getClient().ListUsersetsForPermission(permissionId, listParams)
+}
+
+// CheckUserHasPermissionInTenant checks whether a user holds a permission
+// within a tenant, counting roles assigned to the user within that tenant
+// (see role.AssignRoleToUserInTenant) as well as global grants. options, which
+// may be nil, set the Warrant-Token and the request's context.
+func (c Client) CheckUserHasPermissionInTenant(permissionId string, userId string, tenantId string, options *warrant.RequestOptions) (bool, error) {
+	checkParams := &warrant.PermissionCheckParams{
+		PermissionId: permissionId,
+		UserId:       userId,
+		Context:      warrant.TenantScopeContext(tenantId),
+	}
+	if options != nil {
+		checkParams.RequestOptions = *options
+	}
+	return c.warrantClient.CheckUserHasPermission(checkParams)
+}
+
+func CheckUserHasPermissionInTenant(permissionId string, userId string, tenantId string, options *warrant.RequestOptions) (bool, error) {
+	return getClient().CheckUserHasPermissionInTenant(permissionId, userId, tenantId, options)
+}
+
+const (
+	SourceDirect      = "direct"
+	SourceRole        = warrant.ObjectTypeRole
+	SourceTenant      = warrant.ObjectTypeTenant
+	SourcePricingTier = warrant.ObjectTypePricingTier
+	SourceImplicit    = "implicit"
+)
+
+type EffectivePermissionsOptions struct {
+	// The longest chain of warrants followed from the user to a permission.
+	// Defaults to 5.
+	MaxDepth int
+	// Object types, beyond roles, tenants and pricing tiers, whose members
+	// inherit the object's permissions, such as "group". Like tenants, they
+	// only pass on permissions granted to a userset such as group:eng#member.
+	// Roles and pricing tiers also pass on permissions granted to the object
+	// itself, as the built-in object types define.
+	ObjectTypes []string
+	// Skip the query for permissions that no chain of warrants explains.
+	SkipImplicit bool
+}
+
+type EffectivePermission struct {
+	PermissionId string
+	Meta         map[string]interface{}
+	Grants       []PermissionGrant
+}
+
+// PermissionGrant is one way a user holds a permission. Chain lists the
+// warrants from the user to the permission, so a permission granted to a role
+// the user is a member of has the user's role warrant first and the role's
+// permission warrant last.
+type PermissionGrant struct {
+	// SourceDirect, SourceImplicit or the object type the chain starts
+	// with, such as SourceRole.
+	Source string
+	Chain  []warrant.Warrant
+	// Whether any warrant in the chain has a policy, so that the grant
+	// depends on the context of a check.
+	Conditional bool
+}
+
+// memberInheritedTypes are the built-in object types whose members inherit
+// the permissions granted to the object itself, such as role:admin, and not
+// just those granted to a userset such as role:admin#member.
+var memberInheritedTypes = map[string]bool{
+	warrant.ObjectTypeRole:        true,
+	warrant.ObjectTypePricingTier: true,
+}
+
+type permissionNode struct {
+	subject warrant.Subject
+	chain   []warrant.Warrant
+}
+
+// inChain reports whether the chain from the user already passes through
+// object, so that following it again would loop.
+func (node permissionNode) inChain(objectType string, objectId string) bool {
+	if len(node.chain) == 0 {
+		return node.subject.ObjectType == objectType && node.subject.ObjectId == objectId
+	}
+	if node.chain[0].Subject.ObjectType == objectType && node.chain[0].Subject.ObjectId == objectId {
+		return true
+	}
+	for _, chainWarrant := range node.chain {
+		if chainWarrant.ObjectType == objectType && chainWarrant.ObjectId == objectId {
+			return true
+		}
+	}
+	return false
+}
+
+// reaches reports whether a warrant on the node's object reaches the user. A
+// warrant on a userset, such as tenant:acme#admin, only reaches the subjects
+// holding that relation, and a warrant on the object itself only reaches its
+// members if the object type passes them on.
+func (node permissionNode) reaches(subject warrant.Subject) bool {
+	if subject.Relation == node.subject.Relation {
+		return true
+	}
+	return subject.Relation == "" && node.subject.Relation == "member" && memberInheritedTypes[node.subject.ObjectType]
+}
+
+// EffectivePermissions lists every permission a user holds along with how they
+// hold it: directly, through roles, tenants and pricing tiers (or the other
+// ObjectTypes), and through anything else the query engine infers. Each
+// permission is listed once, with a grant for every chain of warrants that
+// reaches the user, up to MaxDepth warrants long.
+func (c Client) EffectivePermissions(userId string, options *EffectivePermissionsOptions) ([]EffectivePermission, error) {
+	if options == nil {
+		options = &EffectivePermissionsOptions{}
+	}
+	maxDepth := options.MaxDepth
+	if maxDepth <= 0 {
+		maxDepth = 5
+	}
+	inheritedTypes := map[string]bool{
+		warrant.ObjectTypeRole:        true,
+		warrant.ObjectTypeTenant:      true,
+		warrant.ObjectTypePricingTier: true,
+	}
+	for _, objectType := range options.ObjectTypes {
+		inheritedTypes[objectType] = true
+	}
+
+	warrantClient := warrant.NewClient(c.apiClient.Config)
+	permissions := make(map[string]*EffectivePermission)
+	subjectWarrants := make(map[warrant.Subject][]warrant.Warrant)
+	queue := []permissionNode{{
+		subject: warrant.Subject{
+			ObjectType: warrant.ObjectTypeUser,
+			ObjectId:   userId,
+		},
+	}}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+
+		object := warrant.Subject{
+			ObjectType: node.subject.ObjectType,
+			ObjectId:   node.subject.ObjectId,
+		}
+		warrants, ok := subjectWarrants[object]
+		if !ok {
+			var err error
+			warrants, err = warrantClient.ListAll(&warrant.ListWarrantParams{SubjectType: object.ObjectType, SubjectId: object.ObjectId})
+			if err != nil {
+				return nil, err
+			}
+			subjectWarrants[object] = warrants
+		}
+		for _, foundWarrant := range warrants {
+			if !node.reaches(foundWarrant.Subject) {
+				continue
+			}
+			chain := make([]warrant.Warrant, 0, len(node.chain)+1)
+			chain = append(append(chain, node.chain...), foundWarrant)
+			if foundWarrant.ObjectType == warrant.ObjectTypePermission {
+				addGrant(permissions, foundWarrant.ObjectId, newGrant(chain))
+				continue
+			}
+			if inheritedTypes[foundWarrant.ObjectType] && len(chain) < maxDepth && !node.inChain(foundWarrant.ObjectType, foundWarrant.ObjectId) {
+				queue = append(queue, permissionNode{
+					subject: warrant.Subject{
+						ObjectType: foundWarrant.ObjectType,
+						ObjectId:   foundWarrant.ObjectId,
+						Relation:   foundWarrant.Relation,
+					},
+					chain: chain,
+				})
+			}
+		}
+	}
+
+	metaLoaded := make(map[string]bool)
+	if !options.SkipImplicit {
+		queryResults, err := warrantClient.QueryAll(fmt.Sprintf("select permission where user:%s is *", userId), nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, queryResult := range queryResults {
+			effectivePermission, ok := permissions[queryResult.ObjectId]
+			if !ok {
+				chain := make([]warrant.Warrant, 0, 1)
+				if queryResult.Warrant.ObjectType != "" {
+					chain = append(chain, queryResult.Warrant)
+				}
+				grant := newGrant(chain)
+				grant.Source = SourceImplicit
+				effectivePermission = addGrant(permissions, queryResult.ObjectId, grant)
+			}
+			effectivePermission.Meta = queryResult.Meta
+			metaLoaded[queryResult.ObjectId] = true
+		}
+	}
+	for permissionId, effectivePermission := range permissions {
+		if metaLoaded[permissionId] {
+			continue
+		}
+		foundPermission, err := c.Get(permissionId, nil)
+		if err != nil {
+			return nil, err
+		}
+		effectivePermission.Meta = foundPermission.Meta
+	}
+
+	effectivePermissions := make([]EffectivePermission, 0, len(permissions))
+	for _, effectivePermission := range permissions {
+		effectivePermissions = append(effectivePermissions, *effectivePermission)
+	}
+	sort.Slice(effectivePermissions, func(i, j int) bool {
+		return effectivePermissions[i].PermissionId < effectivePermissions[j].PermissionId
+	})
+	return effectivePermissions, nil
+}
+
+func EffectivePermissions(userId string, options *EffectivePermissionsOptions) ([]EffectivePermission, error) {
+	return getClient().EffectivePermissions(userId, options)
+}
+
+func newGrant(chain []warrant.Warrant) PermissionGrant {
+	grant := PermissionGrant{
+		Source: SourceDirect,
+		Chain:  chain,
+	}
+	if len(chain) > 1 {
+		grant.Source = chain[0].ObjectType
+	}
+	for _, chainWarrant := range chain {
+		if chainWarrant.Policy != "" {
+			grant.Conditional = true
+		}
+	}
+	return grant
+}
+
+func addGrant(permissions map[string]*EffectivePermission, permissionId string, grant PermissionGrant) *EffectivePermission {
+	effectivePermission, ok := permissions[permissionId]
+	if !ok {
+		effectivePermission = &EffectivePermission{
+			PermissionId: permissionId,
+		}
+		permissions[permissionId] = effectivePermission
+	}
+	effectivePermission.Grants = append(effectivePermission.Grants, grant)
+	return effectivePermission
+}
+
+// TypedClient decodes permission meta into M on reads and validates and encodes
+// it on writes. The ObjectId of each result is the permission's id. It wraps
+// object.TypeClient.
+type TypedClient[M any] struct {
+	client object.TypeClient[M]
+}
+
+func Typed[M any](c Client) TypedClient[M] {
+	return TypedClient[M]{
+		client: object.TypedFor[M](object.NewClient(c.apiClient.Config), warrant.ObjectTypePermission),
+	}
+}
+
+func (c TypedClient[M]) Get(permissionId string, params *warrant.PermissionParams) (*warrant.ObjectOf[M], error) {
+	if params == nil {
+		params = &warrant.PermissionParams{}
+	}
+	return c.client.Get(permissionId, params.RequestOptions)
+}
+
+func GetAs[M any](permissionId string, params *warrant.PermissionParams) (*warrant.ObjectOf[M], error) {
+	return Typed[M](getClient()).Get(permissionId, params)
+}
+
+func (c TypedClient[M]) List(listParams *warrant.ListPermissionParams) (warrant.ListResponse[warrant.ObjectOf[M]], error) {
+	if listParams == nil {
+		listParams = &warrant.ListPermissionParams{}
+	}
+	return c.client.List(listParams.ListParams)
+}
+
+func ListAs[M any](listParams *warrant.ListPermissionParams) (warrant.ListResponse[warrant.ObjectOf[M]], error) {
+	return Typed[M](getClient()).List(listParams)
+}
+
+func (c TypedClient[M]) Create(typedObject *warrant.ObjectOf[M]) (*warrant.ObjectOf[M], error) {
+	return c.client.Create(typedObject)
+}
+
+func CreateAs[M any](typedObject *warrant.ObjectOf[M]) (*warrant.ObjectOf[M], error) {
+	return Typed[M](getClient()).Create(typedObject)
+}
+
+func (c TypedClient[M]) Update(typedObject *warrant.ObjectOf[M]) (*warrant.ObjectOf[M], error) {
+	return c.client.Update(typedObject)
+}
+
+func UpdateAs[M any](typedObject *warrant.ObjectOf[M]) (*warrant.ObjectOf[M], error) {
+	return Typed[M](getClient()).Update(typedObject)
+}
+
 func getClient() Client {
 	config := warrant.ClientConfig{
 		ApiKey:                  warrant.ApiKey,
@@ -278,9 +658,10 @@ func getClient() Client {
 	}
 
 	return Client{
-		&warrant.ApiClient{
+		apiClient: &warrant.ApiClient{
 			HttpClient: warrant.HttpClient,
 			Config:     config,
 		},
+		warrantClient: warrant.NewClient(config),
 	}
 }
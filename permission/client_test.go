@@ -0,0 +1,222 @@
+package permission
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/warrant-dev/warrant-go/v6"
+)
+
+// graphServer serves a fixed set of warrants, filtered by the list query's
+// subject, and the results of any query. Permissions are returned with their
+// id as the name in their meta.
+type graphServer struct {
+	warrants     []warrant.Warrant
+	queryResults []warrant.QueryResult
+
+	mu       sync.Mutex
+	requests []string
+}
+
+func (server *graphServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	server.mu.Lock()
+	server.requests = append(server.requests, r.URL.RequestURI())
+	server.mu.Unlock()
+
+	switch {
+	case r.URL.Path == "/v2/warrants":
+		query := r.URL.Query()
+		results := make([]warrant.Warrant, 0)
+		for _, stored := range server.warrants {
+			if stored.Subject.ObjectType == query.Get("subjectType") && stored.Subject.ObjectId == query.Get("subjectId") {
+				results = append(results, stored)
+			}
+		}
+		json.NewEncoder(w).Encode(warrant.ListResponse[warrant.Warrant]{Results: results})
+	case r.URL.Path == "/v2/query":
+		json.NewEncoder(w).Encode(warrant.ListResponse[warrant.QueryResult]{Results: server.queryResults})
+	case strings.HasPrefix(r.URL.Path, "/v2/objects/permission/"):
+		permissionId := strings.TrimPrefix(r.URL.Path, "/v2/objects/permission/")
+		json.NewEncoder(w).Encode(warrant.Object{ObjectType: "permission", ObjectId: permissionId, Meta: map[string]interface{}{"name": permissionId}})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (server *graphServer) countRequests(prefix string) int {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	count := 0
+	for _, request := range server.requests {
+		if strings.HasPrefix(request, prefix) {
+			count++
+		}
+	}
+	return count
+}
+
+func newTestClient(t *testing.T, server *graphServer) Client {
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+	return NewClient(warrant.ClientConfig{ApiKey: "key", ApiEndpoint: httpServer.URL})
+}
+
+func grant(objectType string, objectId string, subject string) warrant.Warrant {
+	subjectType, subjectId, _ := strings.Cut(subject, ":")
+	subjectId, subjectRelation, _ := strings.Cut(subjectId, "#")
+	return warrant.Warrant{
+		ObjectType: objectType,
+		ObjectId:   objectId,
+		Relation:   "member",
+		Subject:    warrant.Subject{ObjectType: subjectType, ObjectId: subjectId, Relation: subjectRelation},
+	}
+}
+
+func permissionIds(effectivePermissions []EffectivePermission) []string {
+	ids := make([]string, 0, len(effectivePermissions))
+	for _, effectivePermission := range effectivePermissions {
+		ids = append(ids, effectivePermission.PermissionId)
+	}
+	return ids
+}
+
+func TestEffectivePermissions(t *testing.T) {
+	assert := assert.New(t)
+	conditional := grant("permission", "export", "user:1")
+	conditional.Policy = `ip == "10.0.0.1"`
+	server := &graphServer{
+		warrants: []warrant.Warrant{
+			grant("permission", "view", "user:1"),
+			conditional,
+			grant("role", "admin", "user:1"),
+			grant("permission", "edit", "role:admin"),
+			grant("tenant", "acme", "user:1"),
+			grant("permission", "billing", "tenant:acme#member"),
+			// Permissions granted to a tenant itself don't reach its members.
+			grant("permission", "delete-tenant", "tenant:acme"),
+		},
+	}
+	client := newTestClient(t, server)
+
+	effectivePermissions, err := client.EffectivePermissions("1", &EffectivePermissionsOptions{SkipImplicit: true})
+	assert.NoError(err)
+	assert.Equal([]string{"billing", "edit", "export", "view"}, permissionIds(effectivePermissions))
+	byId := make(map[string]EffectivePermission)
+	for _, effectivePermission := range effectivePermissions {
+		byId[effectivePermission.PermissionId] = effectivePermission
+		assert.Equal(effectivePermission.PermissionId, effectivePermission.Meta["name"])
+	}
+
+	if assert.Len(byId["view"].Grants, 1) {
+		assert.Equal(SourceDirect, byId["view"].Grants[0].Source)
+		assert.False(byId["view"].Grants[0].Conditional)
+	}
+	if assert.Len(byId["export"].Grants, 1) {
+		assert.True(byId["export"].Grants[0].Conditional)
+	}
+	if assert.Len(byId["edit"].Grants, 1) {
+		assert.Equal(SourceRole, byId["edit"].Grants[0].Source)
+		assert.Equal([]warrant.Warrant{grant("role", "admin", "user:1"), grant("permission", "edit", "role:admin")}, byId["edit"].Grants[0].Chain)
+	}
+	if assert.Len(byId["billing"].Grants, 1) {
+		assert.Equal(SourceTenant, byId["billing"].Grants[0].Source)
+		assert.Len(byId["billing"].Grants[0].Chain, 2)
+	}
+	assert.Zero(server.countRequests("/v2/query"))
+}
+
+func TestEffectivePermissionsStopsAtCycles(t *testing.T) {
+	assert := assert.New(t)
+	server := &graphServer{
+		warrants: []warrant.Warrant{
+			grant("role", "a", "user:1"),
+			grant("role", "b", "role:a"),
+			grant("role", "a", "role:b"),
+			grant("permission", "view", "role:b"),
+		},
+	}
+	client := newTestClient(t, server)
+
+	effectivePermissions, err := client.EffectivePermissions("1", &EffectivePermissionsOptions{SkipImplicit: true})
+	assert.NoError(err)
+	if assert.Len(effectivePermissions, 1) {
+		assert.Len(effectivePermissions[0].Grants, 1)
+		assert.Len(effectivePermissions[0].Grants[0].Chain, 3)
+	}
+	// Each subject is listed once: the user, role:a and role:b.
+	assert.Equal(3, server.countRequests("/v2/warrants"))
+}
+
+func TestEffectivePermissionsMaxDepth(t *testing.T) {
+	assert := assert.New(t)
+	server := &graphServer{
+		warrants: []warrant.Warrant{
+			grant("role", "a", "user:1"),
+			grant("role", "b", "role:a"),
+			grant("permission", "view", "role:b"),
+		},
+	}
+	client := newTestClient(t, server)
+
+	effectivePermissions, err := client.EffectivePermissions("1", &EffectivePermissionsOptions{MaxDepth: 2, SkipImplicit: true})
+	assert.NoError(err)
+	assert.Empty(effectivePermissions)
+
+	effectivePermissions, err = client.EffectivePermissions("1", &EffectivePermissionsOptions{MaxDepth: 3, SkipImplicit: true})
+	assert.NoError(err)
+	assert.Equal([]string{"view"}, permissionIds(effectivePermissions))
+}
+
+func TestEffectivePermissionsObjectTypes(t *testing.T) {
+	assert := assert.New(t)
+	server := &graphServer{
+		warrants: []warrant.Warrant{
+			grant("group", "eng", "user:1"),
+			grant("permission", "deploy", "group:eng#member"),
+		},
+	}
+	client := newTestClient(t, server)
+
+	effectivePermissions, err := client.EffectivePermissions("1", &EffectivePermissionsOptions{SkipImplicit: true})
+	assert.NoError(err)
+	assert.Empty(effectivePermissions)
+
+	effectivePermissions, err = client.EffectivePermissions("1", &EffectivePermissionsOptions{ObjectTypes: []string{"group"}, SkipImplicit: true})
+	assert.NoError(err)
+	if assert.Equal([]string{"deploy"}, permissionIds(effectivePermissions)) {
+		assert.Equal("group", effectivePermissions[0].Grants[0].Source)
+	}
+}
+
+func TestEffectivePermissionsImplicit(t *testing.T) {
+	assert := assert.New(t)
+	server := &graphServer{
+		warrants: []warrant.Warrant{
+			grant("permission", "view", "user:1"),
+		},
+		queryResults: []warrant.QueryResult{
+			{ObjectType: "permission", ObjectId: "view", Meta: map[string]interface{}{"name": "View"}},
+			{ObjectType: "permission", ObjectId: "audit", IsImplicit: true, Meta: map[string]interface{}{"name": "Audit"}},
+		},
+	}
+	client := newTestClient(t, server)
+
+	effectivePermissions, err := client.EffectivePermissions("1", nil)
+	assert.NoError(err)
+	if assert.Equal([]string{"audit", "view"}, permissionIds(effectivePermissions)) {
+		assert.Equal(SourceImplicit, effectivePermissions[0].Grants[0].Source)
+		assert.Empty(effectivePermissions[0].Grants[0].Chain)
+		assert.Equal("Audit", effectivePermissions[0].Meta["name"])
+		assert.Equal(SourceDirect, effectivePermissions[1].Grants[0].Source)
+		assert.Equal("View", effectivePermissions[1].Meta["name"])
+	}
+	assert.Equal(1, server.countRequests("/v2/query"))
+	// Meta came from the query, so no permission was fetched.
+	assert.Zero(server.countRequests("/v2/objects"))
+	assert.True(strings.HasPrefix(server.requests[1], "/v2/query?q=select+permission+where+user%3A1+is+%2A&"))
+}
@@ -31,7 +31,7 @@ func (c Client) Create(params *warrant.PricingTierParams) (*warrant.PricingTier,
 	if params.Meta != nil {
 		objectParams.Meta = params.Meta
 	}
-	object, err := object.Create(&objectParams)
+	object, err := object.NewClient(c.apiClient.Config).Create(&objectParams)
 	if err != nil {
 		return nil, err
 	}
@@ -55,7 +55,7 @@ func (c Client) Get(pricingTierId string, params *warrant.PricingTierParams) (*w
 		RequestOptions: params.RequestOptions,
 		Meta:           params.Meta,
 	}
-	object, err := object.Get(warrant.ObjectTypePricingTier, pricingTierId, &objectParams)
+	object, err := object.NewClient(c.apiClient.Config).Get(warrant.ObjectTypePricingTier, pricingTierId, &objectParams)
 	if err != nil {
 		return nil, err
 	}
@@ -79,7 +79,7 @@ func (c Client) Update(pricingTierId string, params *warrant.PricingTierParams)
 		RequestOptions: params.RequestOptions,
 		Meta:           params.Meta,
 	}
-	object, err := object.Update(warrant.ObjectTypePricingTier, pricingTierId, &objectParams)
+	object, err := object.NewClient(c.apiClient.Config).Update(warrant.ObjectTypePricingTier, pricingTierId, &objectParams)
 	if err != nil {
 		return nil, err
 	}
@@ -93,8 +93,48 @@ func Update(pricingTierId string, params *warrant.PricingTierParams) (*warrant.P
 	return getClient().Update(pricingTierId, params)
 }
 
+func (c Client) Upsert(params *warrant.PricingTierParams) (*warrant.UpsertResult[warrant.PricingTier], error) {
+	if params == nil {
+		params = &warrant.PricingTierParams{}
+	}
+	return object.UpsertAs(object.NewClient(c.apiClient.Config), &warrant.ObjectParams{
+		RequestOptions: params.RequestOptions,
+		ObjectType:     warrant.ObjectTypePricingTier,
+		ObjectId:       params.PricingTierId,
+		Meta:           params.Meta,
+	}, pricingTierFromObject)
+}
+
+func Upsert(params *warrant.PricingTierParams) (*warrant.UpsertResult[warrant.PricingTier], error) {
+	return getClient().Upsert(params)
+}
+
+func (c Client) BatchUpsert(params []warrant.PricingTierParams) ([]warrant.UpsertResult[warrant.PricingTier], error) {
+	objectsToUpsert := make([]warrant.ObjectParams, 0)
+	for _, pricingTierParam := range params {
+		objectsToUpsert = append(objectsToUpsert, warrant.ObjectParams{
+			RequestOptions: pricingTierParam.RequestOptions,
+			ObjectType:     warrant.ObjectTypePricingTier,
+			ObjectId:       pricingTierParam.PricingTierId,
+			Meta:           pricingTierParam.Meta,
+		})
+	}
+	return object.BatchUpsertAs(object.NewClient(c.apiClient.Config), objectsToUpsert, pricingTierFromObject)
+}
+
+func BatchUpsert(params []warrant.PricingTierParams) ([]warrant.UpsertResult[warrant.PricingTier], error) {
+	return getClient().BatchUpsert(params)
+}
+
+func pricingTierFromObject(pricingTierObject warrant.Object) warrant.PricingTier {
+	return warrant.PricingTier{
+		PricingTierId: pricingTierObject.ObjectId,
+		Meta:          pricingTierObject.Meta,
+	}
+}
+
 func (c Client) Delete(pricingTierId string) (string, error) {
-	return object.Delete(warrant.ObjectTypePricingTier, pricingTierId)
+	return object.NewClient(c.apiClient.Config).Delete(warrant.ObjectTypePricingTier, pricingTierId)
 }
 
 func Delete(pricingTierId string) (string, error) {
@@ -107,7 +147,7 @@ func (c Client) ListPricingTiers(listParams *warrant.ListPricingTierParams) (war
 	}
 	var pricingTiersListResponse warrant.ListResponse[warrant.PricingTier]
 
-	objectsListResponse, err := object.ListObjects(&warrant.ListObjectParams{
+	objectsListResponse, err := object.NewClient(c.apiClient.Config).ListObjects(&warrant.ListObjectParams{
 		ListParams: listParams.ListParams,
 		ObjectType: warrant.ObjectTypePricingTier,
 	})
@@ -142,7 +182,7 @@ func (c Client) ListPricingTiersForTenant(tenantId string, listParams *warrant.L
 	}
 	var pricingTiersListResponse warrant.ListResponse[warrant.PricingTier]
 
-	queryResponse, err := warrant.Query(fmt.Sprintf("select pricing-tier where tenant:%s is *", tenantId), &warrant.QueryParams{
+	queryResponse, err := warrant.NewClient(c.apiClient.Config).Query(fmt.Sprintf("select pricing-tier where tenant:%s is *", tenantId), &warrant.QueryParams{
 		ListParams: listParams.ListParams,
 	})
 	if err != nil {
@@ -208,7 +248,7 @@ func (c Client) ListPricingTiersForUser(userId string, listParams *warrant.ListP
 	}
 	var pricingTiersListResponse warrant.ListResponse[warrant.PricingTier]
 
-	queryResponse, err := warrant.Query(fmt.Sprintf("select pricing-tier where user:%s is *", userId), &warrant.QueryParams{
+	queryResponse, err := warrant.NewClient(c.apiClient.Config).Query(fmt.Sprintf("select pricing-tier where user:%s is *", userId), &warrant.QueryParams{
 		ListParams: listParams.ListParams,
 	})
 	if err != nil {
@@ -268,6 +308,93 @@ func RemovePricingTierFromUser(pricingTierId string, userId string) (string, err
 	return getClient().RemovePricingTierFromUser(pricingTierId, userId)
 }
 
+// AssignPricingTierToSubject assigns a pricing tier to any subject, including a
+// userset such as warrant.Userset("tenant", "acme", "admin").
+func (c Client) AssignPricingTierToSubject(pricingTierId string, subject warrant.Subject) (*warrant.Warrant, error) {
+	return warrant.NewClient(c.apiClient.Config).Create(&warrant.WarrantParams{
+		ObjectType: warrant.ObjectTypePricingTier,
+		ObjectId:   pricingTierId,
+		Relation:   "member",
+		Subject:    subject,
+	})
+}
+
+func AssignPricingTierToSubject(pricingTierId string, subject warrant.Subject) (*warrant.Warrant, error) {
+	return getClient().AssignPricingTierToSubject(pricingTierId, subject)
+}
+
+func (c Client) RemovePricingTierFromSubject(pricingTierId string, subject warrant.Subject) (string, error) {
+	return warrant.NewClient(c.apiClient.Config).Delete(&warrant.WarrantParams{
+		ObjectType: warrant.ObjectTypePricingTier,
+		ObjectId:   pricingTierId,
+		Relation:   "member",
+		Subject:    subject,
+	})
+}
+
+func RemovePricingTierFromSubject(pricingTierId string, subject warrant.Subject) (string, error) {
+	return getClient().RemovePricingTierFromSubject(pricingTierId, subject)
+}
+
+func (c Client) ListUsersetsForPricingTier(pricingTierId string, listParams *warrant.ListWarrantParams) (warrant.ListResponse[warrant.Subject], error) {
+	return warrant.NewClient(c.apiClient.Config).ListUsersets(warrant.ObjectTypePricingTier, pricingTierId, "member", listParams)
+}
+
+func ListUsersetsForPricingTier(pricingTierId string, listParams *warrant.ListWarrantParams) (warrant.ListResponse[warrant.Subject], error) {
+	return getClient().ListUsersetsForPricingTier(pricingTierId, listParams)
+}
+
+// TypedClient decodes pricing tier meta into M on reads and validates and encodes
+// it on writes. The ObjectId of each result is the pricing tier's id. It wraps
+// object.TypeClient.
+type TypedClient[M any] struct {
+	client object.TypeClient[M]
+}
+
+func Typed[M any](c Client) TypedClient[M] {
+	return TypedClient[M]{
+		client: object.TypedFor[M](object.NewClient(c.apiClient.Config), warrant.ObjectTypePricingTier),
+	}
+}
+
+func (c TypedClient[M]) Get(pricingTierId string, params *warrant.PricingTierParams) (*warrant.ObjectOf[M], error) {
+	if params == nil {
+		params = &warrant.PricingTierParams{}
+	}
+	return c.client.Get(pricingTierId, params.RequestOptions)
+}
+
+func GetAs[M any](pricingTierId string, params *warrant.PricingTierParams) (*warrant.ObjectOf[M], error) {
+	return Typed[M](getClient()).Get(pricingTierId, params)
+}
+
+func (c TypedClient[M]) List(listParams *warrant.ListPricingTierParams) (warrant.ListResponse[warrant.ObjectOf[M]], error) {
+	if listParams == nil {
+		listParams = &warrant.ListPricingTierParams{}
+	}
+	return c.client.List(listParams.ListParams)
+}
+
+func ListAs[M any](listParams *warrant.ListPricingTierParams) (warrant.ListResponse[warrant.ObjectOf[M]], error) {
+	return Typed[M](getClient()).List(listParams)
+}
+
+func (c TypedClient[M]) Create(typedObject *warrant.ObjectOf[M]) (*warrant.ObjectOf[M], error) {
+	return c.client.Create(typedObject)
+}
+
+func CreateAs[M any](typedObject *warrant.ObjectOf[M]) (*warrant.ObjectOf[M], error) {
+	return Typed[M](getClient()).Create(typedObject)
+}
+
+func (c TypedClient[M]) Update(typedObject *warrant.ObjectOf[M]) (*warrant.ObjectOf[M], error) {
+	return c.client.Update(typedObject)
+}
+
+func UpdateAs[M any](typedObject *warrant.ObjectOf[M]) (*warrant.ObjectOf[M], error) {
+	return Typed[M](getClient()).Update(typedObject)
+}
+
 func getClient() Client {
 	config := warrant.ClientConfig{
 		ApiKey:                  warrant.ApiKey,
@@ -0,0 +1,129 @@
+package warrant
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var defaultLatencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+type latencyHistogram struct {
+	buckets []uint64
+	count   uint64
+	sum     float64
+}
+
+// PrometheusInstrumentation aggregates request metrics in memory and serves
+// them in the Prometheus text exposition format, so no Prometheus client
+// library is needed. Mount it on any http.ServeMux, e.g. at /metrics.
+type PrometheusInstrumentation struct {
+	mu         sync.Mutex
+	buckets    []float64
+	latencies  map[InstrumentationTags]*latencyHistogram
+	errorCount map[InstrumentationTags]uint64
+}
+
+func NewPrometheusInstrumentation(buckets ...float64) *PrometheusInstrumentation {
+	if len(buckets) == 0 {
+		buckets = defaultLatencyBuckets
+	}
+	sortedBuckets := append([]float64(nil), buckets...)
+	sort.Float64s(sortedBuckets)
+	return &PrometheusInstrumentation{
+		buckets:    sortedBuckets,
+		latencies:  make(map[InstrumentationTags]*latencyHistogram),
+		errorCount: make(map[InstrumentationTags]uint64),
+	}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) End(err error) {}
+
+func (instrumentation *PrometheusInstrumentation) StartSpan(ctx context.Context, tags InstrumentationTags) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+func (instrumentation *PrometheusInstrumentation) RecordLatency(tags InstrumentationTags, latency time.Duration) {
+	instrumentation.mu.Lock()
+	defer instrumentation.mu.Unlock()
+	histogram, ok := instrumentation.latencies[tags]
+	if !ok {
+		histogram = &latencyHistogram{
+			buckets: make([]uint64, len(instrumentation.buckets)),
+		}
+		instrumentation.latencies[tags] = histogram
+	}
+	seconds := latency.Seconds()
+	for i, upperBound := range instrumentation.buckets {
+		if seconds <= upperBound {
+			histogram.buckets[i]++
+		}
+	}
+	histogram.count++
+	histogram.sum += seconds
+}
+
+func (instrumentation *PrometheusInstrumentation) IncError(tags InstrumentationTags, err error) {
+	instrumentation.mu.Lock()
+	defer instrumentation.mu.Unlock()
+	instrumentation.errorCount[tags]++
+}
+
+func (instrumentation *PrometheusInstrumentation) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	fmt.Fprint(w, instrumentation.String())
+}
+
+func (instrumentation *PrometheusInstrumentation) String() string {
+	instrumentation.mu.Lock()
+	defer instrumentation.mu.Unlock()
+
+	var out strings.Builder
+	out.WriteString("# HELP warrant_client_request_duration_seconds Latency of Warrant API requests made by the SDK.\n")
+	out.WriteString("# TYPE warrant_client_request_duration_seconds histogram\n")
+	for _, tags := range sortedTags(instrumentation.latencies) {
+		histogram := instrumentation.latencies[tags]
+		labels := prometheusLabels(tags)
+		for i, upperBound := range instrumentation.buckets {
+			fmt.Fprintf(&out, "warrant_client_request_duration_seconds_bucket{%s,le=\"%s\"} %d\n", labels, strconv.FormatFloat(upperBound, 'g', -1, 64), histogram.buckets[i])
+		}
+		fmt.Fprintf(&out, "warrant_client_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, histogram.count)
+		fmt.Fprintf(&out, "warrant_client_request_duration_seconds_sum{%s} %s\n", labels, strconv.FormatFloat(histogram.sum, 'g', -1, 64))
+		fmt.Fprintf(&out, "warrant_client_request_duration_seconds_count{%s} %d\n", labels, histogram.count)
+	}
+
+	out.WriteString("# HELP warrant_client_errors_total Failed Warrant API requests made by the SDK.\n")
+	out.WriteString("# TYPE warrant_client_errors_total counter\n")
+	for _, tags := range sortedTags(instrumentation.errorCount) {
+		fmt.Fprintf(&out, "warrant_client_errors_total{%s} %d\n", prometheusLabels(tags), instrumentation.errorCount[tags])
+	}
+	return out.String()
+}
+
+func sortedTags[V any](metrics map[InstrumentationTags]V) []InstrumentationTags {
+	tags := make([]InstrumentationTags, 0, len(metrics))
+	for tag := range metrics {
+		tags = append(tags, tag)
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		return prometheusLabels(tags[i]) < prometheusLabels(tags[j])
+	})
+	return tags
+}
+
+var prometheusLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func prometheusLabels(tags InstrumentationTags) string {
+	return fmt.Sprintf(`operation="%s",object_type="%s",relation="%s",outcome="%s"`,
+		prometheusLabelEscaper.Replace(tags.Operation),
+		prometheusLabelEscaper.Replace(tags.ObjectType),
+		prometheusLabelEscaper.Replace(tags.Relation),
+		prometheusLabelEscaper.Replace(tags.Outcome))
+}
@@ -0,0 +1,175 @@
+package warrant
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type RateLimitSettings struct {
+	// Sustained requests per second and the burst allowed above it. A zero
+	// RequestsPerSecond disables the token bucket but still honors server
+	// rate-limit headers.
+	RequestsPerSecond float64
+	Burst             int
+	// The most requests allowed in flight at once. Zero means unlimited.
+	MaxInFlight int
+}
+
+// RateLimiter throttles requests on the client before they reach the Warrant
+// API. When the API responds with 429 or reports that its rate limit is
+// exhausted, the limiter pauses until the advertised reset and temporarily
+// halves its rate, recovering gradually as requests succeed.
+type RateLimiter struct {
+	settings RateLimitSettings
+	inFlight chan struct{}
+
+	mu           sync.Mutex
+	rate         float64
+	tokens       float64
+	lastRefill   time.Time
+	blockedUntil time.Time
+}
+
+const (
+	rateLimitMinFactor      = 0.1
+	rateLimitRecoveryFactor = 0.05
+)
+
+func NewRateLimiter(settings RateLimitSettings) *RateLimiter {
+	if settings.Burst <= 0 {
+		settings.Burst = 1
+	}
+	limiter := &RateLimiter{
+		settings:   settings,
+		rate:       settings.RequestsPerSecond,
+		tokens:     float64(settings.Burst),
+		lastRefill: time.Now(),
+	}
+	if settings.MaxInFlight > 0 {
+		limiter.inFlight = make(chan struct{}, settings.MaxInFlight)
+	}
+	return limiter
+}
+
+// Wait blocks until a request may be sent or ctx is done. When it returns
+// nil, the returned function must be called once the request completes.
+func (limiter *RateLimiter) Wait(ctx context.Context) (func(), error) {
+	for {
+		delay := limiter.reserve(time.Now())
+		if delay <= 0 {
+			break
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, WrapError("Rate limit wait cancelled", ctx.Err())
+		case <-timer.C:
+		}
+	}
+
+	if limiter.inFlight == nil {
+		return func() {}, nil
+	}
+	select {
+	case limiter.inFlight <- struct{}{}:
+		return func() { <-limiter.inFlight }, nil
+	case <-ctx.Done():
+		return nil, WrapError("Rate limit wait cancelled", ctx.Err())
+	}
+}
+
+// reserve takes a token if one is available and otherwise returns how long to
+// wait before trying again.
+func (limiter *RateLimiter) reserve(now time.Time) time.Duration {
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+	if now.Before(limiter.blockedUntil) {
+		return limiter.blockedUntil.Sub(now)
+	}
+	if limiter.rate <= 0 {
+		return 0
+	}
+
+	limiter.tokens += now.Sub(limiter.lastRefill).Seconds() * limiter.rate
+	if limiter.tokens > float64(limiter.settings.Burst) {
+		limiter.tokens = float64(limiter.settings.Burst)
+	}
+	limiter.lastRefill = now
+	if limiter.tokens >= 1 {
+		limiter.tokens--
+		return 0
+	}
+	return time.Duration((1 - limiter.tokens) / limiter.rate * float64(time.Second))
+}
+
+func (limiter *RateLimiter) observe(resp *http.Response) {
+	now := time.Now()
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+
+	if resetAt, ok := rateLimitResetAt(resp.Header, now); ok && resetAt.After(limiter.blockedUntil) {
+		if resp.StatusCode == http.StatusTooManyRequests || rateLimitExhausted(resp.Header) {
+			limiter.blockedUntil = resetAt
+		}
+	}
+
+	configuredRate := limiter.settings.RequestsPerSecond
+	if configuredRate <= 0 {
+		return
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		limiter.rate /= 2
+		if limiter.rate < configuredRate*rateLimitMinFactor {
+			limiter.rate = configuredRate * rateLimitMinFactor
+		}
+		limiter.tokens = 0
+	} else if limiter.rate < configuredRate {
+		limiter.rate += configuredRate * rateLimitRecoveryFactor
+		if limiter.rate > configuredRate {
+			limiter.rate = configuredRate
+		}
+	}
+}
+
+func rateLimitExhausted(header http.Header) bool {
+	for _, name := range []string{"RateLimit-Remaining", "X-RateLimit-Remaining"} {
+		if remaining := header.Get(name); remaining != "" {
+			return remaining == "0"
+		}
+	}
+	return false
+}
+
+// rateLimitResetAt reads Retry-After, or else the RateLimit-Reset (seconds
+// from now) or X-RateLimit-Reset (seconds from now or a Unix timestamp)
+// headers.
+func rateLimitResetAt(header http.Header, now time.Time) (time.Time, bool) {
+	if retryAfter := header.Get("Retry-After"); retryAfter != "" {
+		if seconds, err := strconv.ParseFloat(retryAfter, 64); err == nil {
+			return now.Add(time.Duration(seconds * float64(time.Second))), true
+		}
+		if date, err := http.ParseTime(retryAfter); err == nil {
+			return date, true
+		}
+	}
+	for _, name := range []string{"RateLimit-Reset", "X-RateLimit-Reset"} {
+		reset := header.Get(name)
+		if reset == "" {
+			continue
+		}
+		seconds, err := strconv.ParseInt(reset, 10, 64)
+		if err != nil {
+			continue
+		}
+		// Values this large can only be Unix timestamps.
+		if seconds > 1000000000 {
+			return time.Unix(seconds, 0), true
+		}
+		return now.Add(time.Duration(seconds) * time.Second), true
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,137 @@
+package warrant
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiterTokenBucket(t *testing.T) {
+	assert := assert.New(t)
+	limiter := NewRateLimiter(RateLimitSettings{RequestsPerSecond: 10, Burst: 2})
+	now := limiter.lastRefill
+
+	assert.Zero(limiter.reserve(now))
+	assert.Zero(limiter.reserve(now))
+	assert.Equal(100*time.Millisecond, limiter.reserve(now))
+	assert.Equal(50*time.Millisecond, limiter.reserve(now.Add(50*time.Millisecond)))
+	assert.Zero(limiter.reserve(now.Add(100 * time.Millisecond)))
+
+	// Idle time refills the bucket only up to the burst.
+	later := now.Add(10 * time.Second)
+	assert.Zero(limiter.reserve(later))
+	assert.Zero(limiter.reserve(later))
+	assert.Greater(limiter.reserve(later), time.Duration(0))
+}
+
+func TestRateLimiterWaitBlocksUntilTokenOrContextDone(t *testing.T) {
+	assert := assert.New(t)
+	limiter := NewRateLimiter(RateLimitSettings{RequestsPerSecond: 20, Burst: 1})
+
+	release, err := limiter.Wait(context.Background())
+	assert.NoError(err)
+	release()
+
+	start := time.Now()
+	release, err = limiter.Wait(context.Background())
+	assert.NoError(err)
+	release()
+	assert.GreaterOrEqual(time.Since(start), 40*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	_, err = limiter.Wait(ctx)
+	assert.ErrorIs(err, context.DeadlineExceeded)
+}
+
+func TestRateLimiterMaxInFlight(t *testing.T) {
+	assert := assert.New(t)
+	limiter := NewRateLimiter(RateLimitSettings{MaxInFlight: 1})
+
+	release, err := limiter.Wait(context.Background())
+	assert.NoError(err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = limiter.Wait(ctx)
+	assert.ErrorIs(err, context.DeadlineExceeded)
+
+	release()
+	release, err = limiter.Wait(context.Background())
+	assert.NoError(err)
+	release()
+}
+
+func TestRateLimiterReleasesInFlightSlotOnError(t *testing.T) {
+	assert := assert.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"message":"Invalid object"}`))
+	}))
+	defer server.Close()
+
+	limiter := NewRateLimiter(RateLimitSettings{MaxInFlight: 1})
+	client := NewApiClient(ClientConfig{ApiEndpoint: server.URL, WriteRateLimiter: limiter})
+	for i := 0; i < 3; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		options := &RequestOptions{}
+		options.SetContext(ctx)
+		_, err := client.MakeRequest("POST", "/v2/objects", ObjectParams{ObjectType: "role"}, options)
+		cancel()
+		assert.ErrorContains(err, "Invalid object")
+	}
+	assert.Len(limiter.inFlight, 0)
+}
+
+func rateLimitResponse(statusCode int, header http.Header) *http.Response {
+	return &http.Response{StatusCode: statusCode, Header: header}
+}
+
+func TestRateLimiterObserve(t *testing.T) {
+	tests := []struct {
+		name     string
+		response *http.Response
+		blocked  time.Duration
+		rate     float64
+	}{
+		{"success", rateLimitResponse(http.StatusOK, http.Header{}), 0, 10},
+		{"too many requests", rateLimitResponse(http.StatusTooManyRequests, http.Header{}), 0, 5},
+		{"retry after seconds", rateLimitResponse(http.StatusTooManyRequests, http.Header{"Retry-After": {"2"}}), 2 * time.Second, 5},
+		{"reset when exhausted", rateLimitResponse(http.StatusOK, http.Header{"Ratelimit-Remaining": {"0"}, "Ratelimit-Reset": {"3"}}), 3 * time.Second, 10},
+		{"reset with requests left", rateLimitResponse(http.StatusOK, http.Header{"Ratelimit-Remaining": {"5"}, "Ratelimit-Reset": {"3"}}), 0, 10},
+		{"unix reset", rateLimitResponse(http.StatusOK, http.Header{"X-Ratelimit-Remaining": {"0"}, "X-Ratelimit-Reset": {strconv.FormatInt(time.Now().Add(4*time.Second).Unix(), 10)}}), 4 * time.Second, 10},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			limiter := NewRateLimiter(RateLimitSettings{RequestsPerSecond: 10})
+			limiter.observe(test.response)
+			assert.Equal(t, test.rate, limiter.rate)
+			if test.blocked == 0 {
+				assert.True(t, limiter.blockedUntil.IsZero())
+				return
+			}
+			assert.WithinDuration(t, time.Now().Add(test.blocked), limiter.blockedUntil, time.Second)
+			assert.Greater(t, limiter.reserve(time.Now()), test.blocked-time.Second)
+		})
+	}
+}
+
+func TestRateLimiterBacksOffAndRecovers(t *testing.T) {
+	assert := assert.New(t)
+	limiter := NewRateLimiter(RateLimitSettings{RequestsPerSecond: 10})
+	for i := 0; i < 10; i++ {
+		limiter.observe(rateLimitResponse(http.StatusTooManyRequests, http.Header{}))
+	}
+	assert.Equal(1.0, limiter.rate)
+	assert.Zero(limiter.tokens)
+
+	for i := 0; i < 100; i++ {
+		limiter.observe(rateLimitResponse(http.StatusOK, http.Header{}))
+	}
+	assert.Equal(10.0, limiter.rate)
+}
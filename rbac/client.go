@@ -0,0 +1,554 @@
+package rbac
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/warrant-dev/warrant-go/v6"
+	"github.com/warrant-dev/warrant-go/v6/feature"
+	"github.com/warrant-dev/warrant-go/v6/object"
+	"github.com/warrant-dev/warrant-go/v6/permission"
+	"github.com/warrant-dev/warrant-go/v6/pricingtier"
+	"github.com/warrant-dev/warrant-go/v6/role"
+	"gopkg.in/yaml.v3"
+)
+
+// Policy declares permissions, roles, features and pricing tiers as code.
+// Each declared role's permissions and inherited roles, and each declared
+// pricing tier's features, are the complete set: Apply removes any others.
+// Meta is replaced when set and left as is when omitted. Objects that the
+// policy doesn't declare are never changed.
+type Policy struct {
+	Permissions  []PermissionSpec  `json:"permissions,omitempty" yaml:"permissions,omitempty"`
+	Roles        []RoleSpec        `json:"roles,omitempty" yaml:"roles,omitempty"`
+	Features     []FeatureSpec     `json:"features,omitempty" yaml:"features,omitempty"`
+	PricingTiers []PricingTierSpec `json:"pricingTiers,omitempty" yaml:"pricingTiers,omitempty"`
+}
+
+type PermissionSpec struct {
+	Id   string                 `json:"id" yaml:"id"`
+	Meta map[string]interface{} `json:"meta,omitempty" yaml:"meta,omitempty"`
+}
+
+type RoleSpec struct {
+	Id          string                 `json:"id" yaml:"id"`
+	Meta        map[string]interface{} `json:"meta,omitempty" yaml:"meta,omitempty"`
+	Permissions []string               `json:"permissions,omitempty" yaml:"permissions,omitempty"`
+	// Roles whose members and permissions this role includes, as with
+	// role.AssignRoleToRole(inheritedRoleId, roleId).
+	Inherits []string `json:"inherits,omitempty" yaml:"inherits,omitempty"`
+}
+
+type FeatureSpec struct {
+	Id   string                 `json:"id" yaml:"id"`
+	Meta map[string]interface{} `json:"meta,omitempty" yaml:"meta,omitempty"`
+}
+
+type PricingTierSpec struct {
+	Id       string                 `json:"id" yaml:"id"`
+	Meta     map[string]interface{} `json:"meta,omitempty" yaml:"meta,omitempty"`
+	Features []string               `json:"features,omitempty" yaml:"features,omitempty"`
+}
+
+// ParsePolicy parses and validates a policy written in YAML or JSON. Unknown
+// fields are rejected so that typos don't go unnoticed.
+func ParsePolicy(data []byte) (*Policy, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	var policy Policy
+	err := decoder.Decode(&policy)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, warrant.WrapError("Invalid RBAC policy", err)
+	}
+	err = policy.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// LoadPolicy reads and parses a policy file.
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, warrant.WrapError(fmt.Sprintf("Unable to read RBAC policy %s", path), err)
+	}
+	return ParsePolicy(data)
+}
+
+// Validate checks that ids are set and unique, that every referenced
+// permission, role and feature is declared, and that no role inherits itself.
+func (policy Policy) Validate() error {
+	problems := make([]string, 0)
+	permissionIds := declaredIds(warrant.ObjectTypePermission, len(policy.Permissions), func(i int) string { return policy.Permissions[i].Id }, &problems)
+	roleIds := declaredIds(warrant.ObjectTypeRole, len(policy.Roles), func(i int) string { return policy.Roles[i].Id }, &problems)
+	featureIds := declaredIds(warrant.ObjectTypeFeature, len(policy.Features), func(i int) string { return policy.Features[i].Id }, &problems)
+	declaredIds(warrant.ObjectTypePricingTier, len(policy.PricingTiers), func(i int) string { return policy.PricingTiers[i].Id }, &problems)
+
+	for _, roleSpec := range policy.Roles {
+		for _, permissionId := range roleSpec.Permissions {
+			if !permissionIds[permissionId] {
+				problems = append(problems, fmt.Sprintf("role %s has undeclared permission %s", roleSpec.Id, permissionId))
+			}
+		}
+		for _, inheritedRoleId := range roleSpec.Inherits {
+			if !roleIds[inheritedRoleId] {
+				problems = append(problems, fmt.Sprintf("role %s inherits undeclared role %s", roleSpec.Id, inheritedRoleId))
+			}
+		}
+	}
+	for _, pricingTierSpec := range policy.PricingTiers {
+		for _, featureId := range pricingTierSpec.Features {
+			if !featureIds[featureId] {
+				problems = append(problems, fmt.Sprintf("pricing tier %s has undeclared feature %s", pricingTierSpec.Id, featureId))
+			}
+		}
+	}
+	if cycle := policy.findInheritanceCycle(); cycle != nil {
+		problems = append(problems, fmt.Sprintf("roles inherit each other in a cycle (%s)", strings.Join(cycle, " > ")))
+	}
+
+	if len(problems) > 0 {
+		return warrant.Error{
+			Message: fmt.Sprintf("Invalid RBAC policy: %s", strings.Join(problems, "; ")),
+		}
+	}
+	return nil
+}
+
+func declaredIds(objectType string, count int, id func(int) string, problems *[]string) map[string]bool {
+	ids := make(map[string]bool, count)
+	for i := 0; i < count; i++ {
+		switch {
+		case id(i) == "":
+			*problems = append(*problems, fmt.Sprintf("%s %d has no id", objectType, i))
+		case ids[id(i)]:
+			*problems = append(*problems, fmt.Sprintf("%s %s is declared more than once", objectType, id(i)))
+		}
+		ids[id(i)] = true
+	}
+	return ids
+}
+
+func (policy Policy) findInheritanceCycle() []string {
+	inherits := make(map[string][]string, len(policy.Roles))
+	for _, roleSpec := range policy.Roles {
+		inherits[roleSpec.Id] = roleSpec.Inherits
+	}
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(policy.Roles))
+	path := make([]string, 0)
+	var visit func(roleId string) []string
+	visit = func(roleId string) []string {
+		switch state[roleId] {
+		case visiting:
+			for i, pathRoleId := range path {
+				if pathRoleId == roleId {
+					return append(append([]string{}, path[i:]...), roleId)
+				}
+			}
+		case visited:
+			return nil
+		}
+		state[roleId] = visiting
+		path = append(path, roleId)
+		for _, inheritedRoleId := range inherits[roleId] {
+			if cycle := visit(inheritedRoleId); cycle != nil {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		state[roleId] = visited
+		return nil
+	}
+	for _, roleSpec := range policy.Roles {
+		if cycle := visit(roleSpec.Id); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
+
+type ChangeAction string
+
+const (
+	ActionCreate ChangeAction = "create"
+	ActionUpdate ChangeAction = "update"
+	ActionAssign ChangeAction = "assign"
+	ActionRemove ChangeAction = "remove"
+)
+
+type Change struct {
+	Action     ChangeAction
+	ObjectType string
+	ObjectId   string
+	// The meta written by a create or update.
+	Meta map[string]interface{}
+	// For assign and remove, the role or pricing tier the object is
+	// assigned to or removed from.
+	AssigneeType string
+	AssigneeId   string
+}
+
+func (change Change) String() string {
+	switch change.Action {
+	case ActionAssign:
+		return fmt.Sprintf("assign %s %s to %s %s", change.ObjectType, change.ObjectId, change.AssigneeType, change.AssigneeId)
+	case ActionRemove:
+		return fmt.Sprintf("remove %s %s from %s %s", change.ObjectType, change.ObjectId, change.AssigneeType, change.AssigneeId)
+	}
+	return fmt.Sprintf("%s %s %s", change.Action, change.ObjectType, change.ObjectId)
+}
+
+// PolicyPlan is the ordered list of changes that brings Warrant in line with a
+// policy: creates and meta updates first, then removals, then assignments.
+type PolicyPlan struct {
+	Changes []Change
+}
+
+// String lists the changes one per line, for dry-run output.
+func (plan PolicyPlan) String() string {
+	if len(plan.Changes) == 0 {
+		return "No changes"
+	}
+	lines := make([]string, 0, len(plan.Changes))
+	for _, change := range plan.Changes {
+		lines = append(lines, change.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+type ApplyOptions struct {
+	// Plan the changes without making them.
+	DryRun bool
+}
+
+type ApplyReport struct {
+	Plan    PolicyPlan
+	Applied int
+	DryRun  bool
+}
+
+type Client struct {
+	apiClient *warrant.ApiClient
+}
+
+func NewClient(config warrant.ClientConfig) Client {
+	return Client{
+		apiClient: warrant.NewApiClient(config),
+	}
+}
+
+// ErrNilPolicy is returned by Plan and Apply when they are given no policy.
+var ErrNilPolicy = warrant.Error{
+	Message: "Invalid RBAC policy: policy is nil",
+}
+
+// Plan compares a policy with the permissions, roles, features and pricing
+// tiers in Warrant and returns the fewest changes that make them match.
+func (c Client) Plan(policy *Policy) (*PolicyPlan, error) {
+	if policy == nil {
+		return nil, ErrNilPolicy
+	}
+	err := policy.Validate()
+	if err != nil {
+		return nil, err
+	}
+	warrantClient := warrant.NewClient(c.apiClient.Config)
+	objectChanges := make([]Change, 0)
+	removals := make([]Change, 0)
+	assignments := make([]Change, 0)
+
+	for _, permissionSpec := range policy.Permissions {
+		getParams := &warrant.PermissionParams{}
+		getParams.SetWarrantToken("latest")
+		existingPermission, err := permission.NewClient(c.apiClient.Config).Get(permissionSpec.Id, getParams)
+		change, err := planObject(warrant.ObjectTypePermission, permissionSpec.Id, permissionSpec.Meta, func() map[string]interface{} { return existingPermission.Meta }, err)
+		if err != nil {
+			return nil, err
+		}
+		if change != nil {
+			objectChanges = append(objectChanges, *change)
+		}
+	}
+
+	for _, featureSpec := range policy.Features {
+		getParams := &warrant.FeatureParams{}
+		getParams.SetWarrantToken("latest")
+		existingFeature, err := feature.NewClient(c.apiClient.Config).Get(featureSpec.Id, getParams)
+		change, err := planObject(warrant.ObjectTypeFeature, featureSpec.Id, featureSpec.Meta, func() map[string]interface{} { return existingFeature.Meta }, err)
+		if err != nil {
+			return nil, err
+		}
+		if change != nil {
+			objectChanges = append(objectChanges, *change)
+		}
+	}
+
+	for _, roleSpec := range policy.Roles {
+		getParams := &warrant.RoleParams{}
+		getParams.SetWarrantToken("latest")
+		existingRole, err := role.NewClient(c.apiClient.Config).Get(roleSpec.Id, getParams)
+		change, err := planObject(warrant.ObjectTypeRole, roleSpec.Id, roleSpec.Meta, func() map[string]interface{} { return existingRole.Meta }, err)
+		if err != nil {
+			return nil, err
+		}
+		currentPermissionIds := []string{}
+		currentInheritedRoleIds := []string{}
+		if change != nil {
+			objectChanges = append(objectChanges, *change)
+		}
+		if change == nil || change.Action != ActionCreate {
+			currentPermissionIds, err = assignedIds(warrantClient, warrant.ObjectTypePermission, warrant.ObjectTypeRole, roleSpec.Id, "")
+			if err != nil {
+				return nil, err
+			}
+			currentInheritedRoleIds, err = assignedIds(warrantClient, warrant.ObjectTypeRole, warrant.ObjectTypeRole, roleSpec.Id, "member")
+			if err != nil {
+				return nil, err
+			}
+		}
+		planAssignments(warrant.ObjectTypePermission, roleSpec.Permissions, currentPermissionIds, warrant.ObjectTypeRole, roleSpec.Id, &removals, &assignments)
+		planAssignments(warrant.ObjectTypeRole, roleSpec.Inherits, currentInheritedRoleIds, warrant.ObjectTypeRole, roleSpec.Id, &removals, &assignments)
+	}
+
+	for _, pricingTierSpec := range policy.PricingTiers {
+		getParams := &warrant.PricingTierParams{}
+		getParams.SetWarrantToken("latest")
+		existingPricingTier, err := pricingtier.NewClient(c.apiClient.Config).Get(pricingTierSpec.Id, getParams)
+		change, err := planObject(warrant.ObjectTypePricingTier, pricingTierSpec.Id, pricingTierSpec.Meta, func() map[string]interface{} { return existingPricingTier.Meta }, err)
+		if err != nil {
+			return nil, err
+		}
+		currentFeatureIds := []string{}
+		if change != nil {
+			objectChanges = append(objectChanges, *change)
+		}
+		if change == nil || change.Action != ActionCreate {
+			currentFeatureIds, err = assignedIds(warrantClient, warrant.ObjectTypeFeature, warrant.ObjectTypePricingTier, pricingTierSpec.Id, "")
+			if err != nil {
+				return nil, err
+			}
+		}
+		planAssignments(warrant.ObjectTypeFeature, pricingTierSpec.Features, currentFeatureIds, warrant.ObjectTypePricingTier, pricingTierSpec.Id, &removals, &assignments)
+	}
+
+	changes := make([]Change, 0, len(objectChanges)+len(removals)+len(assignments))
+	changes = append(changes, objectChanges...)
+	changes = append(changes, removals...)
+	changes = append(changes, assignments...)
+	return &PolicyPlan{
+		Changes: changes,
+	}, nil
+}
+
+func Plan(policy *Policy) (*PolicyPlan, error) {
+	return getClient().Plan(policy)
+}
+
+// Apply plans a policy and makes its changes in order. With DryRun set it
+// only plans them. If a change fails, the returned report shows how many
+// were applied.
+func (c Client) Apply(policy *Policy, options *ApplyOptions) (*ApplyReport, error) {
+	if options == nil {
+		options = &ApplyOptions{}
+	}
+	plan, err := c.Plan(policy)
+	if err != nil {
+		return nil, err
+	}
+	report := &ApplyReport{
+		Plan:   *plan,
+		DryRun: options.DryRun,
+	}
+	if options.DryRun {
+		return report, nil
+	}
+
+	for _, change := range plan.Changes {
+		err := c.applyChange(change)
+		if err != nil {
+			return report, warrant.WrapError(fmt.Sprintf("Unable to %s:", change), err)
+		}
+		report.Applied++
+	}
+	return report, nil
+}
+
+func Apply(policy *Policy, options *ApplyOptions) (*ApplyReport, error) {
+	return getClient().Apply(policy, options)
+}
+
+func (c Client) applyChange(change Change) error {
+	var err error
+	switch change.ObjectType {
+	case warrant.ObjectTypePermission:
+		permissionClient := permission.NewClient(c.apiClient.Config)
+		switch change.Action {
+		case ActionCreate:
+			_, err = permissionClient.Create(&warrant.PermissionParams{PermissionId: change.ObjectId, Meta: change.Meta})
+		case ActionUpdate:
+			_, err = permissionClient.Update(change.ObjectId, &warrant.PermissionParams{Meta: change.Meta})
+		case ActionAssign:
+			_, err = permissionClient.AssignPermissionToRole(change.ObjectId, change.AssigneeId)
+		case ActionRemove:
+			_, err = permissionClient.RemovePermissionFromRole(change.ObjectId, change.AssigneeId)
+		}
+	case warrant.ObjectTypeRole:
+		roleClient := role.NewClient(c.apiClient.Config)
+		switch change.Action {
+		case ActionCreate:
+			_, err = roleClient.Create(&warrant.RoleParams{RoleId: change.ObjectId, Meta: change.Meta})
+		case ActionUpdate:
+			_, err = roleClient.Update(change.ObjectId, &warrant.RoleParams{Meta: change.Meta})
+		case ActionAssign:
+			_, err = roleClient.AssignRoleToRole(change.ObjectId, change.AssigneeId)
+		case ActionRemove:
+			_, err = roleClient.RemoveRoleFromRole(change.ObjectId, change.AssigneeId)
+		}
+	case warrant.ObjectTypeFeature:
+		featureClient := feature.NewClient(c.apiClient.Config)
+		switch change.Action {
+		case ActionCreate:
+			_, err = featureClient.Create(&warrant.FeatureParams{FeatureId: change.ObjectId, Meta: change.Meta})
+		case ActionUpdate:
+			_, err = featureClient.Update(change.ObjectId, &warrant.FeatureParams{Meta: change.Meta})
+		case ActionAssign:
+			_, err = featureClient.AssignFeatureToPricingTier(change.ObjectId, change.AssigneeId)
+		case ActionRemove:
+			_, err = featureClient.RemoveFeatureFromPricingTier(change.ObjectId, change.AssigneeId)
+		}
+	case warrant.ObjectTypePricingTier:
+		pricingTierClient := pricingtier.NewClient(c.apiClient.Config)
+		switch change.Action {
+		case ActionCreate:
+			_, err = pricingTierClient.Create(&warrant.PricingTierParams{PricingTierId: change.ObjectId, Meta: change.Meta})
+		case ActionUpdate:
+			_, err = pricingTierClient.Update(change.ObjectId, &warrant.PricingTierParams{Meta: change.Meta})
+		}
+	}
+	return err
+}
+
+// planObject returns the create or update needed for a declared object, or
+// nil if it already matches. getErr is the error from fetching the object.
+func planObject(objectType string, objectId string, meta map[string]interface{}, existingMeta func() map[string]interface{}, getErr error) (*Change, error) {
+	if getErr != nil {
+		var warrantErr warrant.Error
+		if !errors.As(getErr, &warrantErr) || warrantErr.StatusCode != http.StatusNotFound {
+			return nil, getErr
+		}
+		return &Change{
+			Action:     ActionCreate,
+			ObjectType: objectType,
+			ObjectId:   objectId,
+			Meta:       meta,
+		}, nil
+	}
+	if meta == nil || object.MetaEqual(meta, existingMeta()) {
+		return nil, nil
+	}
+	return &Change{
+		Action:     ActionUpdate,
+		ObjectType: objectType,
+		ObjectId:   objectId,
+		Meta:       meta,
+	}, nil
+}
+
+func planAssignments(objectType string, declaredIds []string, currentIds []string, assigneeType string, assigneeId string, removals *[]Change, assignments *[]Change) {
+	declared := make(map[string]bool, len(declaredIds))
+	for _, declaredId := range declaredIds {
+		declared[declaredId] = true
+	}
+	current := make(map[string]bool, len(currentIds))
+	for _, currentId := range currentIds {
+		current[currentId] = true
+	}
+
+	for _, currentId := range currentIds {
+		if !declared[currentId] {
+			*removals = append(*removals, Change{
+				Action:       ActionRemove,
+				ObjectType:   objectType,
+				ObjectId:     currentId,
+				AssigneeType: assigneeType,
+				AssigneeId:   assigneeId,
+			})
+		}
+	}
+	for _, declaredId := range declaredIds {
+		if !current[declaredId] {
+			*assignments = append(*assignments, Change{
+				Action:       ActionAssign,
+				ObjectType:   objectType,
+				ObjectId:     declaredId,
+				AssigneeType: assigneeType,
+				AssigneeId:   assigneeId,
+			})
+		}
+	}
+}
+
+// assignedIds lists the ids of the objectType objects whose member relation
+// is granted to an assignee, in the form the role, permission, feature and
+// pricingtier helpers write it. Conditional warrants aren't managed by
+// policies and are ignored.
+func assignedIds(warrantClient warrant.WarrantClient, objectType string, assigneeType string, assigneeId string, assigneeRelation string) ([]string, error) {
+	listParams := warrant.ListWarrantParams{
+		ObjectType:  objectType,
+		Relation:    "member",
+		SubjectType: assigneeType,
+		SubjectId:   assigneeId,
+	}
+	listParams.Limit = 1000
+	listParams.SetWarrantToken("latest")
+	ids := make([]string, 0)
+	seen := make(map[string]bool)
+	for {
+		listResponse, err := warrantClient.ListWarrants(&listParams)
+		if err != nil {
+			return nil, err
+		}
+		for _, assignedWarrant := range listResponse.Results {
+			if assignedWarrant.Subject.Relation != assigneeRelation || assignedWarrant.Policy != "" || seen[assignedWarrant.ObjectId] {
+				continue
+			}
+			seen[assignedWarrant.ObjectId] = true
+			ids = append(ids, assignedWarrant.ObjectId)
+		}
+		if listResponse.NextCursor == "" {
+			break
+		}
+		listParams.NextCursor = listResponse.NextCursor
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+func getClient() Client {
+	config := warrant.ClientConfig{
+		ApiKey:                  warrant.ApiKey,
+		ApiEndpoint:             warrant.ApiEndpoint,
+		AuthorizeEndpoint:       warrant.AuthorizeEndpoint,
+		SelfServiceDashEndpoint: warrant.SelfServiceDashEndpoint,
+		HttpClient:              warrant.HttpClient,
+	}
+
+	return Client{
+		&warrant.ApiClient{
+			HttpClient: warrant.HttpClient,
+			Config:     config,
+		},
+	}
+}
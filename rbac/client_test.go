@@ -0,0 +1,92 @@
+package rbac
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/warrant-dev/warrant-go/v6"
+)
+
+func TestPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   Policy
+		problems []string
+	}{
+		{
+			name: "valid",
+			policy: Policy{
+				Permissions:  []PermissionSpec{{Id: "view"}, {Id: "edit"}},
+				Roles:        []RoleSpec{{Id: "viewer", Permissions: []string{"view"}}, {Id: "editor", Permissions: []string{"edit"}, Inherits: []string{"viewer"}}},
+				Features:     []FeatureSpec{{Id: "sso"}},
+				PricingTiers: []PricingTierSpec{{Id: "enterprise", Features: []string{"sso"}}},
+			},
+		},
+		{
+			name:     "missing id",
+			policy:   Policy{Permissions: []PermissionSpec{{Id: "view"}, {}}},
+			problems: []string{"permission 1 has no id"},
+		},
+		{
+			name:     "duplicate id",
+			policy:   Policy{Roles: []RoleSpec{{Id: "admin"}, {Id: "admin"}}},
+			problems: []string{"role admin is declared more than once"},
+		},
+		{
+			name:     "undeclared references",
+			policy:   Policy{Roles: []RoleSpec{{Id: "admin", Permissions: []string{"delete"}, Inherits: []string{"owner"}}}, PricingTiers: []PricingTierSpec{{Id: "free", Features: []string{"sso"}}}},
+			problems: []string{"role admin has undeclared permission delete", "role admin inherits undeclared role owner", "pricing tier free has undeclared feature sso"},
+		},
+		{
+			name:     "self inheritance",
+			policy:   Policy{Roles: []RoleSpec{{Id: "admin", Inherits: []string{"admin"}}}},
+			problems: []string{"roles inherit each other in a cycle (admin > admin)"},
+		},
+		{
+			name: "inheritance cycle",
+			policy: Policy{Roles: []RoleSpec{
+				{Id: "viewer"},
+				{Id: "editor", Inherits: []string{"viewer", "admin"}},
+				{Id: "admin", Inherits: []string{"editor"}},
+			}},
+			problems: []string{"roles inherit each other in a cycle (editor > admin > editor)"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.policy.Validate()
+			if len(test.problems) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			if assert.Error(t, err) {
+				for _, problem := range test.problems {
+					assert.Contains(t, err.Error(), problem)
+				}
+			}
+		})
+	}
+}
+
+func TestPolicyInheritanceWithoutCycle(t *testing.T) {
+	policy := Policy{Roles: []RoleSpec{
+		{Id: "a", Inherits: []string{"b", "c"}},
+		{Id: "b", Inherits: []string{"d"}},
+		{Id: "c", Inherits: []string{"d"}},
+		{Id: "d"},
+	}}
+	assert.Nil(t, policy.findInheritanceCycle())
+}
+
+func TestParsePolicyRejectsUnknownFields(t *testing.T) {
+	_, err := ParsePolicy([]byte("roles:\n  - id: admin\n    permisions: [view]\n"))
+	assert.ErrorContains(t, err, "permisions")
+}
+
+func TestPlanRejectsNilPolicy(t *testing.T) {
+	client := NewClient(warrant.ClientConfig{ApiKey: "key"})
+	_, err := client.Plan(nil)
+	assert.ErrorIs(t, err, ErrNilPolicy)
+	_, err = client.Apply(nil, nil)
+	assert.ErrorIs(t, err, ErrNilPolicy)
+}
@@ -0,0 +1,146 @@
+package warrant
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"net/http"
+	"time"
+)
+
+// ErrDuplicateOnRetry is returned when a retried create is rejected as a
+// duplicate, meaning an earlier attempt already created the record. Clients
+// resolve it by fetching the existing record where they can.
+var ErrDuplicateOnRetry = errors.New("record already created by an earlier attempt")
+
+type RetrySettings struct {
+	// The most times a request is retried after the Warrant API is
+	// unavailable (network errors, HTTP 429 and 5xx).
+	MaxRetries int
+	// Retries back off exponentially from MinBackoff up to MaxBackoff, with
+	// jitter.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// RetryPolicy retries requests that are safe to repeat: reads, updates,
+// deletes and creates sent with an idempotency key. Creates without one are
+// given a generated key so that they can be retried too. This includes creates
+// of objects without an id, where the Warrant API answers a retry with the
+// record an earlier attempt created under the same key. If such a retry is
+// rejected as a duplicate instead, ErrDuplicateOnRetry is returned unresolved,
+// since a record with a server-generated id cannot be looked up again.
+type RetryPolicy struct {
+	settings RetrySettings
+}
+
+func NewRetryPolicy(settings RetrySettings) *RetryPolicy {
+	if settings.MaxRetries <= 0 {
+		settings.MaxRetries = 3
+	}
+	if settings.MinBackoff <= 0 {
+		settings.MinBackoff = 100 * time.Millisecond
+	}
+	if settings.MaxBackoff < settings.MinBackoff {
+		settings.MaxBackoff = 5 * time.Second
+		if settings.MaxBackoff < settings.MinBackoff {
+			settings.MaxBackoff = settings.MinBackoff
+		}
+	}
+	return &RetryPolicy{
+		settings: settings,
+	}
+}
+
+func (policy *RetryPolicy) backoff(retry int) time.Duration {
+	backoff := policy.settings.MinBackoff << (retry - 1)
+	if backoff <= 0 || backoff > policy.settings.MaxBackoff {
+		backoff = policy.settings.MaxBackoff
+	}
+	jitter, err := rand.Int(rand.Reader, big.NewInt(int64(backoff)/2+1))
+	if err != nil {
+		return backoff
+	}
+	return backoff/2 + time.Duration(jitter.Int64())
+}
+
+func isRetryable(method string, path string, options *RequestOptions) bool {
+	if isIdempotentRead(method, path) || method == http.MethodPut || method == http.MethodDelete {
+		return true
+	}
+	return options != nil && options.IdempotencyKey != ""
+}
+
+func isDuplicate(err error) bool {
+	var warrantErr Error
+	return errors.As(err, &warrantErr) && warrantErr.StatusCode == http.StatusConflict
+}
+
+// MayHaveApplied reports whether a failed attempt could still have been
+// processed by the Warrant API. A 429 or a request the circuit breaker never
+// sent was not processed; a network error or 5xx leaves it unknown.
+func MayHaveApplied(err error) bool {
+	if err == nil || errors.Is(err, ErrCircuitOpen) {
+		return false
+	}
+	var warrantErr Error
+	if errors.As(err, &warrantErr) && warrantErr.StatusCode != 0 {
+		return warrantErr.StatusCode >= http.StatusInternalServerError
+	}
+	return IsUnavailable(err)
+}
+
+func NewIdempotencyKey() string {
+	key := make([]byte, 16)
+	if _, err := rand.Read(key); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(key)
+}
+
+func (client ApiClient) makeRetriedRequest(method string, path string, payload interface{}, options *RequestOptions) (*http.Response, error) {
+	policy := client.Config.RetryPolicy
+	if policy == nil {
+		return client.makeGuardedRequest(method, path, payload, options)
+	}
+
+	if method == http.MethodPost && !isIdempotentRead(method, path) && (options == nil || options.IdempotencyKey == "") {
+		keyedOptions := RequestOptions{}
+		if options != nil {
+			keyedOptions = *options
+		}
+		keyedOptions.IdempotencyKey = NewIdempotencyKey()
+		options = &keyedOptions
+	}
+	if !isRetryable(method, path, options) {
+		return client.makeGuardedRequest(method, path, payload, options)
+	}
+
+	// Set once an earlier attempt carrying this request's idempotency key may
+	// have been applied. Only then can a 409 be the record that attempt
+	// created; otherwise the record already existed and the 409 is returned.
+	keyMayHaveApplied := false
+	for retry := 0; ; retry++ {
+		resp, err := client.makeGuardedRequest(method, path, payload, options)
+		if keyMayHaveApplied && method == http.MethodPost && isDuplicate(err) {
+			return nil, Error{
+				Message:      "Duplicate record on retry",
+				StatusCode:   http.StatusConflict,
+				WrappedError: ErrDuplicateOnRetry,
+			}
+		}
+		keyMayHaveApplied = keyMayHaveApplied || MayHaveApplied(err)
+		if err == nil || retry >= policy.settings.MaxRetries || !IsUnavailable(err) || errors.Is(err, ErrCircuitOpen) {
+			return resp, err
+		}
+
+		timer := time.NewTimer(policy.backoff(retry + 1))
+		select {
+		case <-options.context().Done():
+			timer.Stop()
+			return nil, err
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package warrant
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newSequenceServer answers the nth request (from 0) with statuses[n] and
+// counts the requests it received.
+func newSequenceServer(statuses []int, requests *atomic.Int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(requests.Add(1) - 1)
+		if n >= len(statuses) {
+			n = len(statuses) - 1
+		}
+		w.WriteHeader(statuses[n])
+		w.Write([]byte(`{}`))
+	}))
+}
+
+func newRetryingClient(endpoint string) *ApiClient {
+	return NewApiClient(ClientConfig{
+		ApiEndpoint: endpoint,
+		RetryPolicy: NewRetryPolicy(RetrySettings{MaxRetries: 3, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond}),
+	})
+}
+
+func TestRetriedCreateConflictAfterServerErrorIsDuplicate(t *testing.T) {
+	var requests atomic.Int32
+	server := newSequenceServer([]int{http.StatusServiceUnavailable, http.StatusConflict}, &requests)
+	defer server.Close()
+
+	params := &ObjectParams{ObjectType: "document", ObjectId: "readme"}
+	_, err := newRetryingClient(server.URL).MakeRequest("POST", "/v2/objects", params, &params.RequestOptions)
+	assert.ErrorIs(t, err, ErrDuplicateOnRetry)
+	assert.Equal(t, int32(2), requests.Load())
+}
+
+func TestRetriedCreateConflictAfterRateLimitIsNotDuplicate(t *testing.T) {
+	var requests atomic.Int32
+	server := newSequenceServer([]int{http.StatusTooManyRequests, http.StatusConflict}, &requests)
+	defer server.Close()
+
+	params := &ObjectParams{ObjectType: "document", ObjectId: "readme"}
+	_, err := newRetryingClient(server.URL).MakeRequest("POST", "/v2/objects", params, &params.RequestOptions)
+	assert.NotErrorIs(t, err, ErrDuplicateOnRetry)
+	var warrantErr Error
+	if assert.ErrorAs(t, err, &warrantErr) {
+		assert.Equal(t, http.StatusConflict, warrantErr.StatusCode)
+	}
+}
+
+func TestCreateWithGeneratedIdIsRetriedWithOneKey(t *testing.T) {
+	var requests atomic.Int32
+	keys := make([]string, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		if requests.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"objectType":"document","objectId":"generated"}`))
+	}))
+	defer server.Close()
+
+	client := newRetryingClient(server.URL)
+	params := &ObjectParams{ObjectType: "document"}
+	_, err := client.MakeRequest("POST", "/v2/objects", params, &params.RequestOptions)
+	assert.NoError(t, err)
+	if assert.Len(t, keys, 2) {
+		assert.NotEmpty(t, keys[0])
+		assert.Equal(t, keys[0], keys[1])
+	}
+
+	keys = keys[:0]
+	requests.Store(0)
+	batch := []ObjectParams{{ObjectType: "document", ObjectId: "readme"}, {ObjectType: "document"}}
+	batch[0].SetIdempotencyKey("caller-key")
+	_, err = client.MakeRequest("POST", "/v2/objects", batch, &batch[0].RequestOptions)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"caller-key", "caller-key"}, keys)
+}
+
+func TestWarrantParamsOmitRequestOptions(t *testing.T) {
+	params := WarrantParams{
+		ObjectType: "document",
+		ObjectId:   "readme",
+		Relation:   "viewer",
+		Subject:    Subject{ObjectType: "user", ObjectId: "1"},
+	}
+	params.SetWarrantToken("latest")
+	params.SetIdempotencyKey("key")
+	body, err := json.Marshal(params)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"objectType":"document","objectId":"readme","relation":"viewer","subject":{"objectType":"user","objectId":"1"}}`, string(body))
+}
@@ -3,8 +3,9 @@ package warrant
 const ObjectTypeRole = "role"
 
 type Role struct {
-	RoleId string                 `json:"roleId"`
-	Meta   map[string]interface{} `json:"meta,omitempty"`
+	RoleId     string                 `json:"roleId"`
+	Meta       map[string]interface{} `json:"meta,omitempty"`
+	IsImplicit bool                   `json:"isImplicit,omitempty"`
 }
 
 func (role Role) GetObjectType() string {
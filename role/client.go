@@ -1,7 +1,10 @@
 package role
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
+	"strings"
 
 	"github.com/warrant-dev/warrant-go/v6"
 	"github.com/warrant-dev/warrant-go/v6/object"
@@ -9,11 +12,15 @@ import (
 
 type Client struct {
 	apiClient *warrant.ApiClient
+	// Shared by the client's checks, so that concurrent identical checks
+	// are coalesced when the config enables CoalesceChecks.
+	warrantClient warrant.WarrantClient
 }
 
 func NewClient(config warrant.ClientConfig) Client {
 	return Client{
-		apiClient: warrant.NewApiClient(config),
+		apiClient:     warrant.NewApiClient(config),
+		warrantClient: warrant.NewClient(config),
 	}
 }
 
@@ -31,7 +38,7 @@ func (c Client) Create(params *warrant.RoleParams) (*warrant.Role, error) {
 	if params.Meta != nil {
 		objectParams.Meta = params.Meta
 	}
-	object, err := object.Create(&objectParams)
+	object, err := object.NewClient(c.apiClient.Config).Create(&objectParams)
 	if err != nil {
 		return nil, err
 	}
@@ -55,7 +62,7 @@ func (c Client) Get(roleId string, params *warrant.RoleParams) (*warrant.Role, e
 		RequestOptions: params.RequestOptions,
 		Meta:           params.Meta,
 	}
-	object, err := object.Get(warrant.ObjectTypeRole, roleId, &objectParams)
+	object, err := object.NewClient(c.apiClient.Config).Get(warrant.ObjectTypeRole, roleId, &objectParams)
 	if err != nil {
 		return nil, err
 	}
@@ -79,7 +86,7 @@ func (c Client) Update(roleId string, params *warrant.RoleParams) (*warrant.Role
 		RequestOptions: params.RequestOptions,
 		Meta:           params.Meta,
 	}
-	object, err := object.Update(warrant.ObjectTypeRole, roleId, &objectParams)
+	object, err := object.NewClient(c.apiClient.Config).Update(warrant.ObjectTypeRole, roleId, &objectParams)
 	if err != nil {
 		return nil, err
 	}
@@ -93,8 +100,48 @@ func Update(roleId string, params *warrant.RoleParams) (*warrant.Role, error) {
 	return getClient().Update(roleId, params)
 }
 
+func (c Client) Upsert(params *warrant.RoleParams) (*warrant.UpsertResult[warrant.Role], error) {
+	if params == nil {
+		params = &warrant.RoleParams{}
+	}
+	return object.UpsertAs(object.NewClient(c.apiClient.Config), &warrant.ObjectParams{
+		RequestOptions: params.RequestOptions,
+		ObjectType:     warrant.ObjectTypeRole,
+		ObjectId:       params.RoleId,
+		Meta:           params.Meta,
+	}, roleFromObject)
+}
+
+func Upsert(params *warrant.RoleParams) (*warrant.UpsertResult[warrant.Role], error) {
+	return getClient().Upsert(params)
+}
+
+func (c Client) BatchUpsert(params []warrant.RoleParams) ([]warrant.UpsertResult[warrant.Role], error) {
+	objectsToUpsert := make([]warrant.ObjectParams, 0)
+	for _, roleParam := range params {
+		objectsToUpsert = append(objectsToUpsert, warrant.ObjectParams{
+			RequestOptions: roleParam.RequestOptions,
+			ObjectType:     warrant.ObjectTypeRole,
+			ObjectId:       roleParam.RoleId,
+			Meta:           roleParam.Meta,
+		})
+	}
+	return object.BatchUpsertAs(object.NewClient(c.apiClient.Config), objectsToUpsert, roleFromObject)
+}
+
+func BatchUpsert(params []warrant.RoleParams) ([]warrant.UpsertResult[warrant.Role], error) {
+	return getClient().BatchUpsert(params)
+}
+
+func roleFromObject(roleObject warrant.Object) warrant.Role {
+	return warrant.Role{
+		RoleId: roleObject.ObjectId,
+		Meta:   roleObject.Meta,
+	}
+}
+
 func (c Client) Delete(roleId string) (string, error) {
-	return object.Delete(warrant.ObjectTypeRole, roleId)
+	return object.NewClient(c.apiClient.Config).Delete(warrant.ObjectTypeRole, roleId)
 }
 
 func Delete(roleId string) (string, error) {
@@ -107,7 +154,7 @@ func (c Client) ListRoles(listParams *warrant.ListRoleParams) (warrant.ListRespo
 	}
 	var rolesListResponse warrant.ListResponse[warrant.Role]
 
-	objectsListResponse, err := object.ListObjects(&warrant.ListObjectParams{
+	objectsListResponse, err := object.NewClient(c.apiClient.Config).ListObjects(&warrant.ListObjectParams{
 		ListParams: listParams.ListParams,
 		ObjectType: warrant.ObjectTypeRole,
 	})
@@ -136,13 +183,15 @@ func ListRoles(listParams *warrant.ListRoleParams) (warrant.ListResponse[warrant
 	return getClient().ListRoles(listParams)
 }
 
+// ListRolesForUser lists the roles assigned to a user and the roles they
+// inherit through the role hierarchy, which have IsImplicit set.
 func (c Client) ListRolesForUser(userId string, listParams *warrant.ListRoleParams) (warrant.ListResponse[warrant.Role], error) {
 	if listParams == nil {
 		listParams = &warrant.ListRoleParams{}
 	}
 	var rolesListResponse warrant.ListResponse[warrant.Role]
 
-	queryResponse, err := warrant.Query(fmt.Sprintf("select role where user:%s is *", userId), &warrant.QueryParams{
+	queryResponse, err := warrant.NewClient(c.apiClient.Config).Query(fmt.Sprintf("select role where user:%s is *", userId), &warrant.QueryParams{
 		ListParams: listParams.ListParams,
 	})
 	if err != nil {
@@ -152,8 +201,9 @@ func (c Client) ListRolesForUser(userId string, listParams *warrant.ListRolePara
 	users := make([]warrant.Role, 0)
 	for _, queryResult := range queryResponse.Results {
 		users = append(users, warrant.Role{
-			RoleId: queryResult.ObjectId,
-			Meta:   queryResult.Meta,
+			RoleId:     queryResult.ObjectId,
+			Meta:       queryResult.Meta,
+			IsImplicit: queryResult.IsImplicit,
 		})
 	}
 
@@ -202,6 +252,437 @@ func RemoveRoleFromUser(roleId string, userId string) (string, error) {
 	return getClient().RemoveRoleFromUser(roleId, userId)
 }
 
+// AssignRoleToSubject assigns a role to any subject, including a
+// userset such as warrant.Userset("tenant", "acme", "admin").
+func (c Client) AssignRoleToSubject(roleId string, subject warrant.Subject) (*warrant.Warrant, error) {
+	return warrant.NewClient(c.apiClient.Config).Create(&warrant.WarrantParams{
+		ObjectType: warrant.ObjectTypeRole,
+		ObjectId:   roleId,
+		Relation:   "member",
+		Subject:    subject,
+	})
+}
+
+func AssignRoleToSubject(roleId string, subject warrant.Subject) (*warrant.Warrant, error) {
+	return getClient().AssignRoleToSubject(roleId, subject)
+}
+
+func (c Client) RemoveRoleFromSubject(roleId string, subject warrant.Subject) (string, error) {
+	return warrant.NewClient(c.apiClient.Config).Delete(&warrant.WarrantParams{
+		ObjectType: warrant.ObjectTypeRole,
+		ObjectId:   roleId,
+		Relation:   "member",
+		Subject:    subject,
+	})
+}
+
+func RemoveRoleFromSubject(roleId string, subject warrant.Subject) (string, error) {
+	return getClient().RemoveRoleFromSubject(roleId, subject)
+}
+
+func (c Client) ListUsersetsForRole(roleId string, listParams *warrant.ListWarrantParams) (warrant.ListResponse[warrant.Subject], error) {
+	return warrant.NewClient(c.apiClient.Config).ListUsersets(warrant.ObjectTypeRole, roleId, "member", listParams)
+}
+
+func ListUsersetsForRole(roleId string, listParams *warrant.ListWarrantParams) (warrant.ListResponse[warrant.Subject], error) {
+	return getClient().ListUsersetsForRole(roleId, listParams)
+}
+
+// AssignRoleToUserInTenant assigns a role to a user only within a tenant. The
+// role applies to checks made with warrant.TenantScopeContext(tenantId).
+func (c Client) AssignRoleToUserInTenant(roleId string, userId string, tenantId string) (*warrant.Warrant, error) {
+	return warrant.NewClient(c.apiClient.Config).Create(&warrant.WarrantParams{
+		ObjectType: warrant.ObjectTypeRole,
+		ObjectId:   roleId,
+		Relation:   "member",
+		Subject: warrant.Subject{
+			ObjectType: warrant.ObjectTypeUser,
+			ObjectId:   userId,
+		},
+		Policy: warrant.TenantScopePolicy(tenantId),
+	})
+}
+
+func AssignRoleToUserInTenant(roleId string, userId string, tenantId string) (*warrant.Warrant, error) {
+	return getClient().AssignRoleToUserInTenant(roleId, userId, tenantId)
+}
+
+func (c Client) RemoveRoleFromUserInTenant(roleId string, userId string, tenantId string) (string, error) {
+	return warrant.NewClient(c.apiClient.Config).Delete(&warrant.WarrantParams{
+		ObjectType: warrant.ObjectTypeRole,
+		ObjectId:   roleId,
+		Relation:   "member",
+		Subject: warrant.Subject{
+			ObjectType: warrant.ObjectTypeUser,
+			ObjectId:   userId,
+		},
+		Policy: warrant.TenantScopePolicy(tenantId),
+	})
+}
+
+func RemoveRoleFromUserInTenant(roleId string, userId string, tenantId string) (string, error) {
+	return getClient().RemoveRoleFromUserInTenant(roleId, userId, tenantId)
+}
+
+// ListRolesForUserInTenant lists the roles assigned to a user within a
+// tenant. Roles assigned to the user globally apply within every tenant and
+// are listed by ListRolesForUser. The user's role warrants are filtered as
+// they are read, and pages are read until listParams.Limit roles (25 by
+// default) are found or the warrants run out, so a page is only short or
+// empty when there is nothing more to list. Paging with a PrevCursor reads
+// backwards the same way.
+func (c Client) ListRolesForUserInTenant(userId string, tenantId string, listParams *warrant.ListRoleParams) (warrant.ListResponse[warrant.Role], error) {
+	if listParams == nil {
+		listParams = &warrant.ListRoleParams{}
+	}
+	limit := listParams.Limit
+	if limit <= 0 {
+		limit = 25
+	}
+	backwards := listParams.PrevCursor != ""
+	warrantClient := warrant.NewClient(c.apiClient.Config)
+
+	var rolesListResponse warrant.ListResponse[warrant.Role]
+	roles := make([]warrant.Role, 0)
+	pageParams := listParams.ListParams
+	for page := 0; ; page++ {
+		// Never read more warrants than there is room for, so the cursors
+		// of the last page read are also the cursors of the result.
+		pageParams.Limit = limit - len(roles)
+		warrantsListResponse, err := warrantClient.ListWarrants(&warrant.ListWarrantParams{
+			ListParams:  pageParams,
+			ObjectType:  warrant.ObjectTypeRole,
+			Relation:    "member",
+			SubjectType: warrant.ObjectTypeUser,
+			SubjectId:   userId,
+		})
+		if err != nil {
+			return rolesListResponse, err
+		}
+
+		pageRoles := make([]warrant.Role, 0)
+		for _, roleWarrant := range warrantsListResponse.Results {
+			if !warrant.IsTenantScopePolicy(roleWarrant.Policy, tenantId) {
+				continue
+			}
+			pageRoles = append(pageRoles, warrant.Role{
+				RoleId: roleWarrant.ObjectId,
+			})
+		}
+
+		var cursor string
+		if backwards {
+			roles = append(pageRoles, roles...)
+			rolesListResponse.PrevCursor = warrantsListResponse.PrevCursor
+			if page == 0 {
+				rolesListResponse.NextCursor = warrantsListResponse.NextCursor
+			}
+			cursor = warrantsListResponse.PrevCursor
+			pageParams.PrevCursor = cursor
+		} else {
+			roles = append(roles, pageRoles...)
+			rolesListResponse.NextCursor = warrantsListResponse.NextCursor
+			if page == 0 {
+				rolesListResponse.PrevCursor = warrantsListResponse.PrevCursor
+			}
+			cursor = warrantsListResponse.NextCursor
+			pageParams.NextCursor = cursor
+		}
+		if len(roles) >= limit || cursor == "" {
+			break
+		}
+	}
+
+	rolesListResponse.Results = roles
+	return rolesListResponse, nil
+}
+
+func ListRolesForUserInTenant(userId string, tenantId string, listParams *warrant.ListRoleParams) (warrant.ListResponse[warrant.Role], error) {
+	return getClient().ListRolesForUserInTenant(userId, tenantId, listParams)
+}
+
+// CheckUserHasRoleInTenant checks whether a user holds a role within a tenant,
+// either through a tenant-scoped assignment or a global one. options, which
+// may be nil, set the Warrant-Token and the request's context.
+func (c Client) CheckUserHasRoleInTenant(roleId string, userId string, tenantId string, options *warrant.RequestOptions) (bool, error) {
+	checkParams := &warrant.RoleCheckParams{
+		RoleId:  roleId,
+		UserId:  userId,
+		Context: warrant.TenantScopeContext(tenantId),
+	}
+	if options != nil {
+		checkParams.RequestOptions = *options
+	}
+	return c.warrantClient.CheckUserHasRole(checkParams)
+}
+
+func CheckUserHasRoleInTenant(roleId string, userId string, tenantId string, options *warrant.RequestOptions) (bool, error) {
+	return getClient().CheckUserHasRoleInTenant(roleId, userId, tenantId, options)
+}
+
+// ErrRoleCycle is wrapped by the error AssignRoleToRole returns when the
+// assignment would make a role include itself.
+var ErrRoleCycle = errors.New("role hierarchy cycle")
+
+// AssignRoleToRole makes every member of parentRoleId a member of roleId, so
+// that the parent role includes roleId and its permissions. It fails with
+// ErrRoleCycle, without writing anything, if roleId already includes
+// parentRoleId.
+func (c Client) AssignRoleToRole(roleId string, parentRoleId string) (*warrant.Warrant, error) {
+	warrantClient := warrant.NewClient(c.apiClient.Config)
+	cycle, err := findRolePath(warrantClient, roleId, parentRoleId)
+	if err != nil {
+		return nil, err
+	}
+	if cycle != nil {
+		return nil, warrant.Error{
+			Message:      fmt.Sprintf("Assigning role %s to role %s would create a cycle (%s)", roleId, parentRoleId, strings.Join(append(cycle, roleId), " > ")),
+			StatusCode:   http.StatusBadRequest,
+			WrappedError: ErrRoleCycle,
+		}
+	}
+	return warrantClient.Create(&warrant.WarrantParams{
+		ObjectType: warrant.ObjectTypeRole,
+		ObjectId:   roleId,
+		Relation:   "member",
+		Subject:    warrant.Userset(warrant.ObjectTypeRole, parentRoleId, "member"),
+	})
+}
+
+func AssignRoleToRole(roleId string, parentRoleId string) (*warrant.Warrant, error) {
+	return getClient().AssignRoleToRole(roleId, parentRoleId)
+}
+
+func (c Client) RemoveRoleFromRole(roleId string, parentRoleId string) (string, error) {
+	return warrant.NewClient(c.apiClient.Config).Delete(&warrant.WarrantParams{
+		ObjectType: warrant.ObjectTypeRole,
+		ObjectId:   roleId,
+		Relation:   "member",
+		Subject:    warrant.Userset(warrant.ObjectTypeRole, parentRoleId, "member"),
+	})
+}
+
+func RemoveRoleFromRole(roleId string, parentRoleId string) (string, error) {
+	return getClient().RemoveRoleFromRole(roleId, parentRoleId)
+}
+
+// ListChildRoles lists the roles directly included by a role.
+func (c Client) ListChildRoles(roleId string, listParams *warrant.ListRoleParams) (warrant.ListResponse[warrant.Role], error) {
+	if listParams == nil {
+		listParams = &warrant.ListRoleParams{}
+	}
+	var rolesListResponse warrant.ListResponse[warrant.Role]
+
+	warrantsListResponse, err := warrant.NewClient(c.apiClient.Config).ListWarrants(&warrant.ListWarrantParams{
+		ListParams:  listParams.ListParams,
+		ObjectType:  warrant.ObjectTypeRole,
+		Relation:    "member",
+		SubjectType: warrant.ObjectTypeRole,
+		SubjectId:   roleId,
+	})
+	if err != nil {
+		return rolesListResponse, err
+	}
+
+	roles := make([]warrant.Role, 0)
+	for _, roleWarrant := range warrantsListResponse.Results {
+		roles = append(roles, warrant.Role{
+			RoleId: roleWarrant.ObjectId,
+		})
+	}
+
+	rolesListResponse = warrant.ListResponse[warrant.Role]{
+		Results:    roles,
+		PrevCursor: warrantsListResponse.PrevCursor,
+		NextCursor: warrantsListResponse.NextCursor,
+	}
+
+	return rolesListResponse, nil
+}
+
+func ListChildRoles(roleId string, listParams *warrant.ListRoleParams) (warrant.ListResponse[warrant.Role], error) {
+	return getClient().ListChildRoles(roleId, listParams)
+}
+
+// ListParentRoles lists the roles that directly include a role.
+func (c Client) ListParentRoles(roleId string, listParams *warrant.ListRoleParams) (warrant.ListResponse[warrant.Role], error) {
+	if listParams == nil {
+		listParams = &warrant.ListRoleParams{}
+	}
+	var rolesListResponse warrant.ListResponse[warrant.Role]
+
+	warrantsListResponse, err := warrant.NewClient(c.apiClient.Config).ListWarrants(&warrant.ListWarrantParams{
+		ListParams:  listParams.ListParams,
+		ObjectType:  warrant.ObjectTypeRole,
+		ObjectId:    roleId,
+		Relation:    "member",
+		SubjectType: warrant.ObjectTypeRole,
+	})
+	if err != nil {
+		return rolesListResponse, err
+	}
+
+	roles := make([]warrant.Role, 0)
+	for _, roleWarrant := range warrantsListResponse.Results {
+		roles = append(roles, warrant.Role{
+			RoleId: roleWarrant.Subject.ObjectId,
+		})
+	}
+
+	rolesListResponse = warrant.ListResponse[warrant.Role]{
+		Results:    roles,
+		PrevCursor: warrantsListResponse.PrevCursor,
+		NextCursor: warrantsListResponse.NextCursor,
+	}
+
+	return rolesListResponse, nil
+}
+
+func ListParentRoles(roleId string, listParams *warrant.ListRoleParams) (warrant.ListResponse[warrant.Role], error) {
+	return getClient().ListParentRoles(roleId, listParams)
+}
+
+type RoleExpansion struct {
+	RoleId string
+	// Every role RoleId includes, directly or through other roles, nearest
+	// first.
+	InheritedRoleIds []string
+	Permissions      []ExpandedPermission
+}
+
+type ExpandedPermission struct {
+	PermissionId string
+	// The roles from RoleId down to the role the permission is assigned
+	// to, following the shortest path.
+	RolePath []string
+}
+
+// ExpandRole lists every role a role includes and every permission assigned
+// to it or to one of those roles.
+func (c Client) ExpandRole(roleId string) (*RoleExpansion, error) {
+	warrantClient := warrant.NewClient(c.apiClient.Config)
+	expansion := &RoleExpansion{
+		RoleId:           roleId,
+		InheritedRoleIds: make([]string, 0),
+		Permissions:      make([]ExpandedPermission, 0),
+	}
+	paths := map[string][]string{roleId: {roleId}}
+	queue := []string{roleId}
+	seenPermissions := make(map[string]bool)
+	for len(queue) > 0 {
+		currentRoleId := queue[0]
+		queue = queue[1:]
+
+		roleWarrants, err := warrantClient.ListAll(&warrant.ListWarrantParams{SubjectType: warrant.ObjectTypeRole, SubjectId: currentRoleId})
+		if err != nil {
+			return nil, err
+		}
+		for _, roleWarrant := range roleWarrants {
+			switch roleWarrant.ObjectType {
+			case warrant.ObjectTypePermission:
+				if seenPermissions[roleWarrant.ObjectId] {
+					continue
+				}
+				seenPermissions[roleWarrant.ObjectId] = true
+				expansion.Permissions = append(expansion.Permissions, ExpandedPermission{
+					PermissionId: roleWarrant.ObjectId,
+					RolePath:     paths[currentRoleId],
+				})
+			case warrant.ObjectTypeRole:
+				if roleWarrant.Relation != "member" || paths[roleWarrant.ObjectId] != nil {
+					continue
+				}
+				paths[roleWarrant.ObjectId] = append(append([]string{}, paths[currentRoleId]...), roleWarrant.ObjectId)
+				expansion.InheritedRoleIds = append(expansion.InheritedRoleIds, roleWarrant.ObjectId)
+				queue = append(queue, roleWarrant.ObjectId)
+			}
+		}
+	}
+	return expansion, nil
+}
+
+func ExpandRole(roleId string) (*RoleExpansion, error) {
+	return getClient().ExpandRole(roleId)
+}
+
+// findRolePath returns the roles from roleId down to includedRoleId if roleId
+// includes it, or nil if it doesn't.
+func findRolePath(warrantClient warrant.WarrantClient, roleId string, includedRoleId string) ([]string, error) {
+	paths := map[string][]string{roleId: {roleId}}
+	queue := []string{roleId}
+	for len(queue) > 0 {
+		currentRoleId := queue[0]
+		queue = queue[1:]
+		if currentRoleId == includedRoleId {
+			return paths[currentRoleId], nil
+		}
+
+		childWarrants, err := warrantClient.ListAll(&warrant.ListWarrantParams{ObjectType: warrant.ObjectTypeRole, Relation: "member", SubjectType: warrant.ObjectTypeRole, SubjectId: currentRoleId})
+		if err != nil {
+			return nil, err
+		}
+		for _, childWarrant := range childWarrants {
+			if paths[childWarrant.ObjectId] != nil {
+				continue
+			}
+			paths[childWarrant.ObjectId] = append(append([]string{}, paths[currentRoleId]...), childWarrant.ObjectId)
+			queue = append(queue, childWarrant.ObjectId)
+		}
+	}
+	return nil, nil
+}
+
+// TypedClient decodes role meta into M on reads and validates and encodes
+// it on writes. The ObjectId of each result is the role's id. It wraps
+// object.TypeClient.
+type TypedClient[M any] struct {
+	client object.TypeClient[M]
+}
+
+func Typed[M any](c Client) TypedClient[M] {
+	return TypedClient[M]{
+		client: object.TypedFor[M](object.NewClient(c.apiClient.Config), warrant.ObjectTypeRole),
+	}
+}
+
+func (c TypedClient[M]) Get(roleId string, params *warrant.RoleParams) (*warrant.ObjectOf[M], error) {
+	if params == nil {
+		params = &warrant.RoleParams{}
+	}
+	return c.client.Get(roleId, params.RequestOptions)
+}
+
+func GetAs[M any](roleId string, params *warrant.RoleParams) (*warrant.ObjectOf[M], error) {
+	return Typed[M](getClient()).Get(roleId, params)
+}
+
+func (c TypedClient[M]) List(listParams *warrant.ListRoleParams) (warrant.ListResponse[warrant.ObjectOf[M]], error) {
+	if listParams == nil {
+		listParams = &warrant.ListRoleParams{}
+	}
+	return c.client.List(listParams.ListParams)
+}
+
+func ListAs[M any](listParams *warrant.ListRoleParams) (warrant.ListResponse[warrant.ObjectOf[M]], error) {
+	return Typed[M](getClient()).List(listParams)
+}
+
+func (c TypedClient[M]) Create(typedObject *warrant.ObjectOf[M]) (*warrant.ObjectOf[M], error) {
+	return c.client.Create(typedObject)
+}
+
+func CreateAs[M any](typedObject *warrant.ObjectOf[M]) (*warrant.ObjectOf[M], error) {
+	return Typed[M](getClient()).Create(typedObject)
+}
+
+func (c TypedClient[M]) Update(typedObject *warrant.ObjectOf[M]) (*warrant.ObjectOf[M], error) {
+	return c.client.Update(typedObject)
+}
+
+func UpdateAs[M any](typedObject *warrant.ObjectOf[M]) (*warrant.ObjectOf[M], error) {
+	return Typed[M](getClient()).Update(typedObject)
+}
+
 func getClient() Client {
 	config := warrant.ClientConfig{
 		ApiKey:                  warrant.ApiKey,
@@ -212,9 +693,10 @@ func getClient() Client {
 	}
 
 	return Client{
-		&warrant.ApiClient{
+		apiClient: &warrant.ApiClient{
 			HttpClient: warrant.HttpClient,
 			Config:     config,
 		},
+		warrantClient: warrant.NewClient(config),
 	}
 }
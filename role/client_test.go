@@ -0,0 +1,237 @@
+package role
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/warrant-dev/warrant-go/v6"
+)
+
+// warrantServer lists a fixed set of warrants, filtered by the list query,
+// and records the warrants created.
+type warrantServer struct {
+	warrants []warrant.Warrant
+
+	mu      sync.Mutex
+	created []warrant.WarrantParams
+}
+
+func (server *warrantServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/v2/warrants" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		query := r.URL.Query()
+		results := make([]warrant.Warrant, 0)
+		for _, stored := range server.warrants {
+			if matches(query.Get("objectType"), stored.ObjectType) &&
+				matches(query.Get("objectId"), stored.ObjectId) &&
+				matches(query.Get("relation"), stored.Relation) &&
+				matches(query.Get("subjectType"), stored.Subject.ObjectType) &&
+				matches(query.Get("subjectId"), stored.Subject.ObjectId) {
+				results = append(results, stored)
+			}
+		}
+		json.NewEncoder(w).Encode(warrant.ListResponse[warrant.Warrant]{Results: results})
+	case http.MethodPost:
+		var params warrant.WarrantParams
+		json.NewDecoder(r.Body).Decode(&params)
+		server.mu.Lock()
+		server.created = append(server.created, params)
+		server.mu.Unlock()
+		json.NewEncoder(w).Encode(warrant.Warrant{ObjectType: params.ObjectType, ObjectId: params.ObjectId, Relation: params.Relation, Subject: params.Subject})
+	}
+}
+
+func matches(filter string, value string) bool {
+	return filter == "" || filter == value
+}
+
+func newTestClient(t *testing.T, server *warrantServer) Client {
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+	return NewClient(warrant.ClientConfig{ApiKey: "key", ApiEndpoint: httpServer.URL})
+}
+
+// includes is the warrant that makes roleId include includedRoleId.
+func includes(roleId string, includedRoleId string) warrant.Warrant {
+	return warrant.Warrant{
+		ObjectType: warrant.ObjectTypeRole,
+		ObjectId:   includedRoleId,
+		Relation:   "member",
+		Subject:    warrant.Userset(warrant.ObjectTypeRole, roleId, "member"),
+	}
+}
+
+func permissionOf(roleId string, permissionId string) warrant.Warrant {
+	return warrant.Warrant{
+		ObjectType: warrant.ObjectTypePermission,
+		ObjectId:   permissionId,
+		Relation:   "member",
+		Subject:    warrant.Userset(warrant.ObjectTypeRole, roleId, "member"),
+	}
+}
+
+func TestAssignRoleToRole(t *testing.T) {
+	assert := assert.New(t)
+	server := &warrantServer{warrants: []warrant.Warrant{includes("admin", "editor")}}
+	client := newTestClient(t, server)
+
+	_, err := client.AssignRoleToRole("viewer", "editor")
+	assert.NoError(err)
+	if assert.Len(server.created, 1) {
+		assert.Equal("viewer", server.created[0].ObjectId)
+		assert.Equal(warrant.Userset(warrant.ObjectTypeRole, "editor", "member"), server.created[0].Subject)
+	}
+}
+
+func TestAssignRoleToItself(t *testing.T) {
+	assert := assert.New(t)
+	server := &warrantServer{}
+	client := newTestClient(t, server)
+
+	_, err := client.AssignRoleToRole("admin", "admin")
+	assert.ErrorIs(err, ErrRoleCycle)
+	assert.ErrorContains(err, "(admin > admin)")
+	assert.Empty(server.created)
+}
+
+func TestAssignRoleToRoleCycle(t *testing.T) {
+	assert := assert.New(t)
+	server := &warrantServer{warrants: []warrant.Warrant{includes("admin", "editor")}}
+	client := newTestClient(t, server)
+
+	_, err := client.AssignRoleToRole("admin", "editor")
+	assert.ErrorIs(err, ErrRoleCycle)
+	assert.ErrorContains(err, "(admin > editor > admin)")
+	var warrantErr warrant.Error
+	if assert.ErrorAs(err, &warrantErr) {
+		assert.Equal(http.StatusBadRequest, warrantErr.StatusCode)
+	}
+	assert.Empty(server.created)
+}
+
+func TestFindRolePath(t *testing.T) {
+	assert := assert.New(t)
+	server := &warrantServer{warrants: []warrant.Warrant{
+		includes("admin", "editor"),
+		includes("editor", "viewer"),
+		includes("admin", "viewer"),
+		includes("viewer", "admin"),
+	}}
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+	warrantClient := warrant.NewClient(warrant.ClientConfig{ApiKey: "key", ApiEndpoint: httpServer.URL})
+
+	path, err := findRolePath(warrantClient, "admin", "viewer")
+	assert.NoError(err)
+	assert.Equal([]string{"admin", "viewer"}, path)
+
+	path, err = findRolePath(warrantClient, "editor", "admin")
+	assert.NoError(err)
+	assert.Equal([]string{"editor", "viewer", "admin"}, path)
+
+	path, err = findRolePath(warrantClient, "viewer", "billing")
+	assert.NoError(err)
+	assert.Nil(path)
+}
+
+func TestExpandRole(t *testing.T) {
+	assert := assert.New(t)
+	server := &warrantServer{warrants: []warrant.Warrant{
+		includes("admin", "editor"),
+		permissionOf("admin", "manage"),
+		includes("editor", "viewer"),
+		permissionOf("editor", "edit"),
+		permissionOf("viewer", "view"),
+		// Already reached through the shorter path from editor.
+		permissionOf("viewer", "edit"),
+		// A cycle back to the top.
+		includes("viewer", "admin"),
+	}}
+	client := newTestClient(t, server)
+
+	expansion, err := client.ExpandRole("admin")
+	assert.NoError(err)
+	assert.Equal("admin", expansion.RoleId)
+	assert.Equal([]string{"editor", "viewer"}, expansion.InheritedRoleIds)
+	assert.Equal([]ExpandedPermission{
+		{PermissionId: "manage", RolePath: []string{"admin"}},
+		{PermissionId: "edit", RolePath: []string{"admin", "editor"}},
+		{PermissionId: "view", RolePath: []string{"admin", "editor", "viewer"}},
+	}, expansion.Permissions)
+
+	expansion, err = client.ExpandRole("viewer")
+	assert.NoError(err)
+	assert.Equal([]string{"admin", "editor"}, expansion.InheritedRoleIds)
+	assert.Equal([]ExpandedPermission{
+		{PermissionId: "view", RolePath: []string{"viewer"}},
+		{PermissionId: "edit", RolePath: []string{"viewer"}},
+		{PermissionId: "manage", RolePath: []string{"viewer", "admin"}},
+	}, expansion.Permissions)
+}
+
+func TestListRolesForUserInTenant(t *testing.T) {
+	assert := assert.New(t)
+	assignment := func(roleId string, policy string) warrant.Warrant {
+		return warrant.Warrant{
+			ObjectType: warrant.ObjectTypeRole,
+			ObjectId:   roleId,
+			Relation:   "member",
+			Subject:    warrant.Subject{ObjectType: warrant.ObjectTypeUser, ObjectId: "1"},
+			Policy:     policy,
+		}
+	}
+	server := &warrantServer{warrants: []warrant.Warrant{
+		assignment("admin", warrant.TenantScopePolicy("acme")),
+		assignment("global", ""),
+		assignment("editor", `tenant=="acme"`),
+		assignment("viewer", warrant.TenantScopePolicy("other")),
+	}}
+	client := newTestClient(t, server)
+
+	roles, err := client.ListRolesForUserInTenant("1", "acme", nil)
+	assert.NoError(err)
+	assert.Equal([]warrant.Role{{RoleId: "admin"}, {RoleId: "editor"}}, roles.Results)
+}
+
+func TestCheckUserHasRoleInTenantCoalesces(t *testing.T) {
+	assert := assert.New(t)
+	var requests atomic.Int32
+	received := make(chan struct{}, 2)
+	release := make(chan struct{})
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		received <- struct{}{}
+		<-release
+		w.Write([]byte(`{"code":200,"result":"Authorized"}`))
+	}))
+	defer httpServer.Close()
+	client := NewClient(warrant.ClientConfig{ApiKey: "key", ApiEndpoint: httpServer.URL, CoalesceChecks: true})
+
+	var wg sync.WaitGroup
+	results := make([]bool, 2)
+	check := func(i int) {
+		defer wg.Done()
+		results[i], _ = client.CheckUserHasRoleInTenant("admin", "1", "acme", nil)
+	}
+	wg.Add(2)
+	go check(0)
+	<-received
+	go check(1)
+	// Give the second check time to join the first.
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal([]bool{true, true}, results)
+	assert.Equal(int32(1), requests.Load())
+}
@@ -0,0 +1,586 @@
+package warrant
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// MetaSchemaRegistry holds a JSON Schema for the meta of each registered
+// object type. Objects are checked against it before every create and update,
+// and invalid meta is never sent to the Warrant API.
+//
+// The supported keywords are type, enum, const, properties, required,
+// additionalProperties, minProperties, maxProperties, items, minItems,
+// maxItems, uniqueItems, minLength, maxLength, pattern, minimum, maximum,
+// exclusiveMinimum, exclusiveMaximum, multipleOf, allOf, anyOf, oneOf and
+// not. Annotations such as title and description are ignored. Any other
+// keyword, such as $ref, $defs, format or patternProperties, is rejected so a
+// schema is never silently enforced less strictly than it reads.
+type MetaSchemaRegistry struct {
+	mu      sync.RWMutex
+	schemas map[string]*metaSchema
+}
+
+func NewMetaSchemaRegistry() *MetaSchemaRegistry {
+	return &MetaSchemaRegistry{
+		schemas: make(map[string]*metaSchema),
+	}
+}
+
+// Register parses schema and uses it for objects of objectType, replacing any
+// schema registered before.
+func (registry *MetaSchemaRegistry) Register(objectType string, schema []byte) error {
+	var document interface{}
+	decoder := json.NewDecoder(bytes.NewReader(schema))
+	decoder.UseNumber()
+	err := decoder.Decode(&document)
+	if err != nil {
+		return WrapError(fmt.Sprintf("Invalid JSON Schema for %s", objectType), err)
+	}
+	compiled, err := compileMetaSchema(document, "#")
+	if err != nil {
+		return WrapError(fmt.Sprintf("Invalid JSON Schema for %s", objectType), err)
+	}
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.schemas[objectType] = compiled
+	return nil
+}
+
+// Validate checks meta against the schema registered for objectType. It
+// returns a ValidationError listing every invalid field, or nil if the meta is
+// valid or no schema is registered.
+func (registry *MetaSchemaRegistry) Validate(objectType string, meta map[string]interface{}) error {
+	fieldErrors, err := registry.validate(objectType, meta, "")
+	if err != nil {
+		return err
+	}
+	if len(fieldErrors) > 0 {
+		return ValidationError{
+			Errors: fieldErrors,
+		}
+	}
+	return nil
+}
+
+// ValidateObjects validates every object in a batch, prefixing each field
+// error with the object's index.
+func (registry *MetaSchemaRegistry) ValidateObjects(objects []ObjectParams) error {
+	fieldErrors := make([]FieldError, 0)
+	for i, object := range objects {
+		objectErrors, err := registry.validate(object.ObjectType, object.Meta, fmt.Sprintf("[%d]", i))
+		if err != nil {
+			return err
+		}
+		fieldErrors = append(fieldErrors, objectErrors...)
+	}
+	if len(fieldErrors) > 0 {
+		return ValidationError{
+			Errors: fieldErrors,
+		}
+	}
+	return nil
+}
+
+func (registry *MetaSchemaRegistry) validate(objectType string, meta map[string]interface{}, path string) ([]FieldError, error) {
+	if registry == nil {
+		return nil, nil
+	}
+	registry.mu.RLock()
+	schema, ok := registry.schemas[objectType]
+	registry.mu.RUnlock()
+	if !ok {
+		return nil, nil
+	}
+
+	// Decode meta the way the Warrant API would receive it.
+	var document interface{} = map[string]interface{}{}
+	if meta != nil {
+		metaJson, err := json.Marshal(meta)
+		if err != nil {
+			return nil, WrapError("Invalid object meta", err)
+		}
+		decoder := json.NewDecoder(bytes.NewReader(metaJson))
+		decoder.UseNumber()
+		err = decoder.Decode(&document)
+		if err != nil {
+			return nil, WrapError("Invalid object meta", err)
+		}
+	}
+	fieldErrors := make([]FieldError, 0)
+	schema.validate(document, path, &fieldErrors)
+	return fieldErrors, nil
+}
+
+type metaSchema struct {
+	// Set for the boolean schemas true and false.
+	allowAll  bool
+	allowNone bool
+
+	types                []string
+	enum                 []interface{}
+	constValue           interface{}
+	hasConst             bool
+	properties           map[string]*metaSchema
+	required             []string
+	additionalProperties *metaSchema
+	minProperties        *int
+	maxProperties        *int
+	items                *metaSchema
+	minItems             *int
+	maxItems             *int
+	uniqueItems          bool
+	minLength            *int
+	maxLength            *int
+	pattern              *regexp.Regexp
+	minimum              *float64
+	maximum              *float64
+	exclusiveMinimum     *float64
+	exclusiveMaximum     *float64
+	multipleOf           *float64
+	allOf                []*metaSchema
+	anyOf                []*metaSchema
+	oneOf                []*metaSchema
+	not                  *metaSchema
+}
+
+func compileMetaSchema(document interface{}, location string) (*metaSchema, error) {
+	if allow, ok := document.(bool); ok {
+		return &metaSchema{allowAll: allow, allowNone: !allow}, nil
+	}
+	keywords, ok := document.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: schema must be an object or a boolean", location)
+	}
+
+	// Compile keywords in order so the same schema always fails the same way.
+	names := make([]string, 0, len(keywords))
+	for keyword := range keywords {
+		names = append(names, keyword)
+	}
+	sort.Strings(names)
+
+	schema := &metaSchema{}
+	var err error
+	for _, keyword := range names {
+		value := keywords[keyword]
+		keywordLocation := location + "/" + keyword
+		switch keyword {
+		case "type":
+			schema.types, err = compileTypes(value, keywordLocation)
+		case "enum":
+			values, ok := value.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("%s: must be an array", keywordLocation)
+			}
+			schema.enum = values
+		case "const":
+			schema.constValue = value
+			schema.hasConst = true
+		case "properties":
+			properties, ok := value.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("%s: must be an object", keywordLocation)
+			}
+			schema.properties = make(map[string]*metaSchema, len(properties))
+			for name, property := range properties {
+				schema.properties[name], err = compileMetaSchema(property, keywordLocation+"/"+name)
+				if err != nil {
+					return nil, err
+				}
+			}
+		case "required":
+			names, ok := value.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("%s: must be an array of strings", keywordLocation)
+			}
+			for _, name := range names {
+				nameString, ok := name.(string)
+				if !ok {
+					return nil, fmt.Errorf("%s: must be an array of strings", keywordLocation)
+				}
+				schema.required = append(schema.required, nameString)
+			}
+		case "additionalProperties":
+			schema.additionalProperties, err = compileMetaSchema(value, keywordLocation)
+		case "items":
+			schema.items, err = compileMetaSchema(value, keywordLocation)
+		case "not":
+			schema.not, err = compileMetaSchema(value, keywordLocation)
+		case "allOf", "anyOf", "oneOf":
+			var subschemas []*metaSchema
+			subschemas, err = compileSubschemas(value, keywordLocation)
+			switch keyword {
+			case "allOf":
+				schema.allOf = subschemas
+			case "anyOf":
+				schema.anyOf = subschemas
+			case "oneOf":
+				schema.oneOf = subschemas
+			}
+		case "minProperties":
+			schema.minProperties, err = compileCount(value, keywordLocation)
+		case "maxProperties":
+			schema.maxProperties, err = compileCount(value, keywordLocation)
+		case "minItems":
+			schema.minItems, err = compileCount(value, keywordLocation)
+		case "maxItems":
+			schema.maxItems, err = compileCount(value, keywordLocation)
+		case "minLength":
+			schema.minLength, err = compileCount(value, keywordLocation)
+		case "maxLength":
+			schema.maxLength, err = compileCount(value, keywordLocation)
+		case "uniqueItems":
+			uniqueItems, ok := value.(bool)
+			if !ok {
+				return nil, fmt.Errorf("%s: must be a boolean", keywordLocation)
+			}
+			schema.uniqueItems = uniqueItems
+		case "pattern":
+			pattern, ok := value.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s: must be a string", keywordLocation)
+			}
+			schema.pattern, err = regexp.Compile(pattern)
+		case "minimum":
+			schema.minimum, err = compileNumber(value, keywordLocation)
+		case "maximum":
+			schema.maximum, err = compileNumber(value, keywordLocation)
+		case "exclusiveMinimum":
+			schema.exclusiveMinimum, err = compileNumber(value, keywordLocation)
+		case "exclusiveMaximum":
+			schema.exclusiveMaximum, err = compileNumber(value, keywordLocation)
+		case "multipleOf":
+			schema.multipleOf, err = compileNumber(value, keywordLocation)
+			if err == nil && *schema.multipleOf <= 0 {
+				err = fmt.Errorf("%s: must be greater than 0", keywordLocation)
+			}
+		default:
+			if !metaSchemaAnnotations[keyword] {
+				err = fmt.Errorf("%s: %s is not supported", location, keyword)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return schema, nil
+}
+
+// metaSchemaAnnotations are keywords that don't affect validation.
+var metaSchemaAnnotations = map[string]bool{
+	"$schema":     true,
+	"$id":         true,
+	"$comment":    true,
+	"title":       true,
+	"description": true,
+	"default":     true,
+	"examples":    true,
+	"deprecated":  true,
+	"readOnly":    true,
+	"writeOnly":   true,
+}
+
+var metaSchemaTypes = map[string]bool{
+	"object":  true,
+	"array":   true,
+	"string":  true,
+	"number":  true,
+	"integer": true,
+	"boolean": true,
+	"null":    true,
+}
+
+func compileTypes(value interface{}, location string) ([]string, error) {
+	var names []interface{}
+	switch typed := value.(type) {
+	case string:
+		names = []interface{}{typed}
+	case []interface{}:
+		names = typed
+	default:
+		return nil, fmt.Errorf("%s: must be a string or an array of strings", location)
+	}
+	types := make([]string, 0, len(names))
+	for _, name := range names {
+		typeName, ok := name.(string)
+		if !ok || !metaSchemaTypes[typeName] {
+			return nil, fmt.Errorf("%s: unknown type %v", location, name)
+		}
+		types = append(types, typeName)
+	}
+	return types, nil
+}
+
+func compileSubschemas(value interface{}, location string) ([]*metaSchema, error) {
+	documents, ok := value.([]interface{})
+	if !ok || len(documents) == 0 {
+		return nil, fmt.Errorf("%s: must be a non-empty array", location)
+	}
+	subschemas := make([]*metaSchema, 0, len(documents))
+	for i, document := range documents {
+		subschema, err := compileMetaSchema(document, fmt.Sprintf("%s/%d", location, i))
+		if err != nil {
+			return nil, err
+		}
+		subschemas = append(subschemas, subschema)
+	}
+	return subschemas, nil
+}
+
+func compileNumber(value interface{}, location string) (*float64, error) {
+	number, ok := value.(json.Number)
+	if !ok {
+		return nil, fmt.Errorf("%s: must be a number", location)
+	}
+	float, err := number.Float64()
+	if err != nil {
+		return nil, fmt.Errorf("%s: must be a number", location)
+	}
+	return &float, nil
+}
+
+func compileCount(value interface{}, location string) (*int, error) {
+	number, err := compileNumber(value, location)
+	if err != nil {
+		return nil, err
+	}
+	if *number < 0 || *number != math.Trunc(*number) {
+		return nil, fmt.Errorf("%s: must be a non-negative integer", location)
+	}
+	count := int(*number)
+	return &count, nil
+}
+
+func (schema *metaSchema) validate(value interface{}, path string, fieldErrors *[]FieldError) {
+	addError := func(rule string, message string, args ...interface{}) {
+		*fieldErrors = append(*fieldErrors, FieldError{
+			Field:   path,
+			Rule:    rule,
+			Message: fmt.Sprintf(message, args...),
+		})
+	}
+	if schema.allowAll {
+		return
+	}
+	if schema.allowNone {
+		addError("false", "is not allowed")
+		return
+	}
+
+	if len(schema.types) > 0 && !matchesAnyType(value, schema.types) {
+		addError("type", "must be of type %s", strings.Join(schema.types, " or "))
+		return
+	}
+	if len(schema.enum) > 0 && !containsJSONValue(schema.enum, value) {
+		addError("enum", "must be one of %s", jsonString(schema.enum))
+	}
+	if schema.hasConst && !jsonValuesEqual(schema.constValue, value) {
+		addError("const", "must equal %s", jsonString(schema.constValue))
+	}
+
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		schema.validateObject(typed, path, fieldErrors, addError)
+	case []interface{}:
+		schema.validateArray(typed, path, fieldErrors, addError)
+	case string:
+		length := utf8.RuneCountInString(typed)
+		if schema.minLength != nil && length < *schema.minLength {
+			addError("minLength", "must have a length of at least %d", *schema.minLength)
+		}
+		if schema.maxLength != nil && length > *schema.maxLength {
+			addError("maxLength", "must have a length of at most %d", *schema.maxLength)
+		}
+		if schema.pattern != nil && !schema.pattern.MatchString(typed) {
+			addError("pattern", "must match the pattern %s", schema.pattern.String())
+		}
+	case json.Number:
+		number, err := typed.Float64()
+		if err != nil {
+			break
+		}
+		if schema.minimum != nil && number < *schema.minimum {
+			addError("minimum", "must be at least %v", *schema.minimum)
+		}
+		if schema.maximum != nil && number > *schema.maximum {
+			addError("maximum", "must be at most %v", *schema.maximum)
+		}
+		if schema.exclusiveMinimum != nil && number <= *schema.exclusiveMinimum {
+			addError("exclusiveMinimum", "must be greater than %v", *schema.exclusiveMinimum)
+		}
+		if schema.exclusiveMaximum != nil && number >= *schema.exclusiveMaximum {
+			addError("exclusiveMaximum", "must be less than %v", *schema.exclusiveMaximum)
+		}
+		if schema.multipleOf != nil {
+			quotient := number / *schema.multipleOf
+			if math.Abs(quotient-math.Round(quotient)) > 1e-9 {
+				addError("multipleOf", "must be a multiple of %v", *schema.multipleOf)
+			}
+		}
+	}
+
+	for _, subschema := range schema.allOf {
+		subschema.validate(value, path, fieldErrors)
+	}
+	if len(schema.anyOf) > 0 && schema.countMatches(schema.anyOf, value) == 0 {
+		addError("anyOf", "must match at least one of the allowed schemas")
+	}
+	if len(schema.oneOf) > 0 && schema.countMatches(schema.oneOf, value) != 1 {
+		addError("oneOf", "must match exactly one of the allowed schemas")
+	}
+	if schema.not != nil && schema.countMatches([]*metaSchema{schema.not}, value) == 1 {
+		addError("not", "must not match the disallowed schema")
+	}
+}
+
+func (schema *metaSchema) validateObject(object map[string]interface{}, path string, fieldErrors *[]FieldError, addError func(rule string, message string, args ...interface{})) {
+	for _, name := range schema.required {
+		if _, ok := object[name]; !ok {
+			*fieldErrors = append(*fieldErrors, FieldError{
+				Field:   joinFieldPath(path, name),
+				Rule:    "required",
+				Message: "is required",
+			})
+		}
+	}
+	if schema.minProperties != nil && len(object) < *schema.minProperties {
+		addError("minProperties", "must have at least %d properties", *schema.minProperties)
+	}
+	if schema.maxProperties != nil && len(object) > *schema.maxProperties {
+		addError("maxProperties", "must have at most %d properties", *schema.maxProperties)
+	}
+
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if property, ok := schema.properties[name]; ok {
+			property.validate(object[name], joinFieldPath(path, name), fieldErrors)
+		} else if schema.additionalProperties != nil {
+			schema.additionalProperties.validate(object[name], joinFieldPath(path, name), fieldErrors)
+		}
+	}
+}
+
+func (schema *metaSchema) validateArray(array []interface{}, path string, fieldErrors *[]FieldError, addError func(rule string, message string, args ...interface{})) {
+	if schema.minItems != nil && len(array) < *schema.minItems {
+		addError("minItems", "must have at least %d items", *schema.minItems)
+	}
+	if schema.maxItems != nil && len(array) > *schema.maxItems {
+		addError("maxItems", "must have at most %d items", *schema.maxItems)
+	}
+	if schema.uniqueItems {
+		seen := make(map[string]bool, len(array))
+		for _, item := range array {
+			key := jsonString(item)
+			if seen[key] {
+				addError("uniqueItems", "must not contain duplicate items")
+				break
+			}
+			seen[key] = true
+		}
+	}
+	if schema.items != nil {
+		for i, item := range array {
+			schema.items.validate(item, fmt.Sprintf("%s[%d]", path, i), fieldErrors)
+		}
+	}
+}
+
+func (schema *metaSchema) countMatches(subschemas []*metaSchema, value interface{}) int {
+	matches := 0
+	for _, subschema := range subschemas {
+		subschemaErrors := make([]FieldError, 0)
+		subschema.validate(value, "", &subschemaErrors)
+		if len(subschemaErrors) == 0 {
+			matches++
+		}
+	}
+	return matches
+}
+
+func matchesAnyType(value interface{}, types []string) bool {
+	for _, typeName := range types {
+		switch typeName {
+		case "object":
+			if _, ok := value.(map[string]interface{}); ok {
+				return true
+			}
+		case "array":
+			if _, ok := value.([]interface{}); ok {
+				return true
+			}
+		case "string":
+			if _, ok := value.(string); ok {
+				return true
+			}
+		case "number":
+			if _, ok := value.(json.Number); ok {
+				return true
+			}
+		case "integer":
+			if number, ok := value.(json.Number); ok {
+				if float, err := number.Float64(); err == nil && float == math.Trunc(float) {
+					return true
+				}
+			}
+		case "boolean":
+			if _, ok := value.(bool); ok {
+				return true
+			}
+		case "null":
+			if value == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func joinFieldPath(path string, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func containsJSONValue(values []interface{}, value interface{}) bool {
+	for _, candidate := range values {
+		if jsonValuesEqual(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonValuesEqual compares two decoded JSON values, treating numbers as equal
+// when they have the same value regardless of how they were written.
+func jsonValuesEqual(a interface{}, b interface{}) bool {
+	aNumber, aIsNumber := a.(json.Number)
+	bNumber, bIsNumber := b.(json.Number)
+	if aIsNumber && bIsNumber {
+		aFloat, aErr := aNumber.Float64()
+		bFloat, bErr := bNumber.Float64()
+		return aErr == nil && bErr == nil && aFloat == bFloat
+	}
+	return jsonString(a) == jsonString(b)
+}
+
+func jsonString(value interface{}) string {
+	valueJson, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(valueJson)
+}
@@ -0,0 +1,126 @@
+package warrant
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetaSchemaRegistryRegister(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		err    string
+	}{
+		{"supported keywords", `{"type":"object","title":"Document","properties":{"title":{"type":"string","description":"Shown in lists","maxLength":10}},"required":["title"]}`, ""},
+		{"boolean schema", `true`, ""},
+		{"invalid json", `{`, "Invalid JSON Schema for document"},
+		{"not a schema", `[]`, "#: schema must be an object or a boolean"},
+		{"ref", `{"$ref":"#/$defs/title"}`, "#: $ref is not supported"},
+		{"defs", `{"$defs":{"title":{"type":"string"}}}`, "#: $defs is not supported"},
+		{"format", `{"properties":{"email":{"type":"string","format":"email"}}}`, "#/properties/email: format is not supported"},
+		{"dependentRequired", `{"dependentRequired":{"a":["b"]}}`, "#: dependentRequired is not supported"},
+		{"patternProperties", `{"patternProperties":{"^x-":{"type":"string"}}}`, "#: patternProperties is not supported"},
+		{"nested unsupported", `{"items":{"anyOf":[{"contains":{"type":"string"}}]}}`, "#/items/anyOf/0: contains is not supported"},
+		{"invalid type", `{"type":"text"}`, "#/type"},
+		{"negative count", `{"minLength":-1}`, "#/minLength: must be a non-negative integer"},
+		{"invalid pattern", `{"pattern":"("}`, "missing closing )"},
+		{"zero multipleOf", `{"multipleOf":0}`, "#/multipleOf: must be greater than 0"},
+		{"non-boolean uniqueItems", `{"uniqueItems":"yes"}`, "#/uniqueItems: must be a boolean"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := NewMetaSchemaRegistry().Register("document", []byte(test.schema))
+			if test.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, test.err)
+			}
+		})
+	}
+}
+
+func TestMetaSchemaRegistryValidate(t *testing.T) {
+	schema := `{
+		"type": "object",
+		"properties": {
+			"title": {"type": "string", "minLength": 1, "maxLength": 10, "pattern": "^[A-Z]"},
+			"status": {"enum": ["draft", "published"]},
+			"kind": {"const": "doc"},
+			"pages": {"type": "integer", "minimum": 1, "exclusiveMaximum": 100, "multipleOf": 2},
+			"tags": {"type": "array", "items": {"type": "string"}, "maxItems": 2, "uniqueItems": true},
+			"owner": {"anyOf": [{"type": "string"}, {"type": "null"}]},
+			"level": {"oneOf": [{"type": "integer"}, {"minimum": 5}]},
+			"draft": {"not": {"const": true}}
+		},
+		"required": ["title"],
+		"additionalProperties": false,
+		"maxProperties": 8
+	}`
+	registry := NewMetaSchemaRegistry()
+	assert.NoError(t, registry.Register("document", []byte(schema)))
+
+	tests := []struct {
+		name   string
+		meta   map[string]interface{}
+		fields []string
+		rules  []string
+	}{
+		{"valid", map[string]interface{}{"title": "Readme", "status": "draft", "kind": "doc", "pages": 2, "tags": []string{"a", "b"}, "owner": nil, "level": 3, "draft": false}, nil, nil},
+		{"missing required", map[string]interface{}{}, []string{"title"}, []string{"required"}},
+		{"nil meta", nil, []string{"title"}, []string{"required"}},
+		{"wrong type", map[string]interface{}{"title": 1}, []string{"title"}, []string{"type"}},
+		{"string rules", map[string]interface{}{"title": "readme for everyone"}, []string{"title", "title"}, []string{"maxLength", "pattern"}},
+		{"enum and const", map[string]interface{}{"title": "Readme", "status": "archived", "kind": "page"}, []string{"kind", "status"}, []string{"const", "enum"}},
+		{"number rules", map[string]interface{}{"title": "Readme", "pages": 101}, []string{"pages", "pages"}, []string{"exclusiveMaximum", "multipleOf"}},
+		{"integer type", map[string]interface{}{"title": "Readme", "pages": 2.5}, []string{"pages"}, []string{"type"}},
+		{"array rules", map[string]interface{}{"title": "Readme", "tags": []interface{}{"a", "a", 1}}, []string{"tags", "tags", "tags[2]"}, []string{"maxItems", "uniqueItems", "type"}},
+		{"anyOf", map[string]interface{}{"title": "Readme", "owner": 1}, []string{"owner"}, []string{"anyOf"}},
+		{"oneOf", map[string]interface{}{"title": "Readme", "level": 6}, []string{"level"}, []string{"oneOf"}},
+		{"not", map[string]interface{}{"title": "Readme", "draft": true}, []string{"draft"}, []string{"not"}},
+		{"additional property", map[string]interface{}{"title": "Readme", "extra": 1}, []string{"extra"}, []string{"false"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := registry.Validate("document", test.meta)
+			if test.fields == nil {
+				assert.NoError(t, err)
+				return
+			}
+			var validationErr ValidationError
+			if assert.ErrorAs(t, err, &validationErr) {
+				fields := make([]string, 0)
+				rules := make([]string, 0)
+				for _, fieldErr := range validationErr.Errors {
+					fields = append(fields, fieldErr.Field)
+					rules = append(rules, fieldErr.Rule)
+				}
+				assert.Equal(t, test.fields, fields)
+				assert.Equal(t, test.rules, rules)
+			}
+		})
+	}
+}
+
+func TestMetaSchemaRegistryUnregisteredType(t *testing.T) {
+	registry := NewMetaSchemaRegistry()
+	assert.NoError(t, registry.Validate("document", map[string]interface{}{"anything": true}))
+
+	var nilRegistry *MetaSchemaRegistry
+	assert.NoError(t, nilRegistry.Validate("document", nil))
+}
+
+func TestMetaSchemaRegistryValidateObjects(t *testing.T) {
+	registry := NewMetaSchemaRegistry()
+	assert.NoError(t, registry.Register("document", []byte(`{"required":["title"]}`)))
+
+	err := registry.ValidateObjects([]ObjectParams{
+		{ObjectType: "document", ObjectId: "a", Meta: map[string]interface{}{"title": "A"}},
+		{ObjectType: "document", ObjectId: "b"},
+		{ObjectType: "user", ObjectId: "1"},
+	})
+	var validationErr ValidationError
+	if assert.ErrorAs(t, err, &validationErr) {
+		assert.Equal(t, []FieldError{{Field: "[1].title", Rule: "required", Message: "is required"}}, validationErr.Errors)
+	}
+}
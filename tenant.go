@@ -3,8 +3,9 @@ package warrant
 const ObjectTypeTenant = "tenant"
 
 type Tenant struct {
-	TenantId string                 `json:"tenantId"`
-	Meta     map[string]interface{} `json:"meta,omitempty"`
+	TenantId     string                 `json:"tenantId"`
+	Meta         map[string]interface{} `json:"meta,omitempty"`
+	WarrantToken string                 `json:"warrantToken,omitempty"`
 }
 
 func (tenant Tenant) GetObjectType() string {
@@ -1,7 +1,9 @@
 package tenant
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
 
 	"github.com/warrant-dev/warrant-go/v6"
 	"github.com/warrant-dev/warrant-go/v6/object"
@@ -31,13 +33,14 @@ func (c Client) Create(params *warrant.TenantParams) (*warrant.Tenant, error) {
 	if params.Meta != nil {
 		objectParams.Meta = params.Meta
 	}
-	object, err := object.Create(&objectParams)
+	object, err := object.NewClient(c.apiClient.Config).Create(&objectParams)
 	if err != nil {
 		return nil, err
 	}
 	return &warrant.Tenant{
-		TenantId: object.ObjectId,
-		Meta:     object.Meta,
+		TenantId:     object.ObjectId,
+		Meta:         object.Meta,
+		WarrantToken: object.WarrantToken,
 	}, nil
 }
 
@@ -56,7 +59,7 @@ func (c Client) BatchCreate(params []warrant.TenantParams) ([]warrant.Tenant, er
 		})
 	}
 
-	createdObjects, err := object.BatchCreate(objectsToCreate)
+	createdObjects, err := object.NewClient(c.apiClient.Config).BatchCreate(objectsToCreate)
 	if err != nil {
 		return nil, err
 	}
@@ -64,8 +67,9 @@ func (c Client) BatchCreate(params []warrant.TenantParams) ([]warrant.Tenant, er
 	tenants := make([]warrant.Tenant, 0)
 	for _, createdObject := range createdObjects {
 		tenants = append(tenants, warrant.Tenant{
-			TenantId: createdObject.ObjectId,
-			Meta:     createdObject.Meta,
+			TenantId:     createdObject.ObjectId,
+			Meta:         createdObject.Meta,
+			WarrantToken: createdObject.WarrantToken,
 		})
 	}
 
@@ -86,7 +90,7 @@ func (c Client) Get(tenantId string, params *warrant.TenantParams) (*warrant.Ten
 		RequestOptions: params.RequestOptions,
 		Meta:           params.Meta,
 	}
-	object, err := object.Get(warrant.ObjectTypeTenant, tenantId, &objectParams)
+	object, err := object.NewClient(c.apiClient.Config).Get(warrant.ObjectTypeTenant, tenantId, &objectParams)
 	if err != nil {
 		return nil, err
 	}
@@ -110,13 +114,14 @@ func (c Client) Update(tenantId string, params *warrant.TenantParams) (*warrant.
 		RequestOptions: params.RequestOptions,
 		Meta:           params.Meta,
 	}
-	object, err := object.Update(warrant.ObjectTypeTenant, tenantId, &objectParams)
+	object, err := object.NewClient(c.apiClient.Config).Update(warrant.ObjectTypeTenant, tenantId, &objectParams)
 	if err != nil {
 		return nil, err
 	}
 	return &warrant.Tenant{
-		TenantId: object.ObjectId,
-		Meta:     object.Meta,
+		TenantId:     object.ObjectId,
+		Meta:         object.Meta,
+		WarrantToken: object.WarrantToken,
 	}, nil
 }
 
@@ -124,8 +129,66 @@ func Update(tenantId string, params *warrant.TenantParams) (*warrant.Tenant, err
 	return getClient().Update(tenantId, params)
 }
 
+func (c Client) Upsert(params *warrant.TenantParams) (*warrant.UpsertResult[warrant.Tenant], error) {
+	if params == nil {
+		params = &warrant.TenantParams{}
+	}
+	return object.UpsertAs(object.NewClient(c.apiClient.Config), &warrant.ObjectParams{
+		RequestOptions: params.RequestOptions,
+		ObjectType:     warrant.ObjectTypeTenant,
+		ObjectId:       params.TenantId,
+		Meta:           params.Meta,
+	}, tenantFromObject)
+}
+
+func Upsert(params *warrant.TenantParams) (*warrant.UpsertResult[warrant.Tenant], error) {
+	return getClient().Upsert(params)
+}
+
+func (c Client) BatchUpsert(params []warrant.TenantParams) ([]warrant.UpsertResult[warrant.Tenant], error) {
+	objectsToUpsert := make([]warrant.ObjectParams, 0)
+	for _, tenantParam := range params {
+		objectsToUpsert = append(objectsToUpsert, warrant.ObjectParams{
+			RequestOptions: tenantParam.RequestOptions,
+			ObjectType:     warrant.ObjectTypeTenant,
+			ObjectId:       tenantParam.TenantId,
+			Meta:           tenantParam.Meta,
+		})
+	}
+	return object.BatchUpsertAs(object.NewClient(c.apiClient.Config), objectsToUpsert, tenantFromObject)
+}
+
+func BatchUpsert(params []warrant.TenantParams) ([]warrant.UpsertResult[warrant.Tenant], error) {
+	return getClient().BatchUpsert(params)
+}
+
+func tenantFromObject(tenantObject warrant.Object) warrant.Tenant {
+	return warrant.Tenant{
+		TenantId:     tenantObject.ObjectId,
+		Meta:         tenantObject.Meta,
+		WarrantToken: tenantObject.WarrantToken,
+	}
+}
+
+// PatchMeta applies an RFC 7396 JSON merge patch to the tenant's meta. See
+// object.PatchMeta.
+func (c Client) PatchMeta(tenantId string, patch map[string]interface{}, options *object.PatchMetaOptions) (*warrant.Tenant, error) {
+	patchedObject, err := object.NewClient(c.apiClient.Config).PatchMeta(warrant.ObjectTypeTenant, tenantId, patch, options)
+	if err != nil {
+		return nil, err
+	}
+	return &warrant.Tenant{
+		TenantId: patchedObject.ObjectId,
+		Meta:     patchedObject.Meta,
+	}, nil
+}
+
+func PatchMeta(tenantId string, patch map[string]interface{}, options *object.PatchMetaOptions) (*warrant.Tenant, error) {
+	return getClient().PatchMeta(tenantId, patch, options)
+}
+
 func (c Client) Delete(tenantId string) (string, error) {
-	return object.Delete(warrant.ObjectTypeTenant, tenantId)
+	return object.NewClient(c.apiClient.Config).Delete(warrant.ObjectTypeTenant, tenantId)
 }
 
 func Delete(tenantId string) (string, error) {
@@ -143,7 +206,7 @@ func (c Client) BatchDelete(params []warrant.TenantParams) (string, error) {
 		})
 	}
 
-	warrantToken, err := object.BatchDelete(objectsToDelete)
+	warrantToken, err := object.NewClient(c.apiClient.Config).BatchDelete(objectsToDelete)
 	if err != nil {
 		return "", err
 	}
@@ -161,7 +224,7 @@ func (c Client) ListTenants(listParams *warrant.ListTenantParams) (warrant.ListR
 	}
 	var tenantsListResponse warrant.ListResponse[warrant.Tenant]
 
-	objectsListResponse, err := object.ListObjects(&warrant.ListObjectParams{
+	objectsListResponse, err := object.NewClient(c.apiClient.Config).ListObjects(&warrant.ListObjectParams{
 		ListParams: listParams.ListParams,
 		ObjectType: warrant.ObjectTypeTenant,
 	})
@@ -196,7 +259,7 @@ func (c Client) ListTenantsForUser(userId string, listParams *warrant.ListTenant
 	}
 	var tenantsListResponse warrant.ListResponse[warrant.Tenant]
 
-	queryResponse, err := warrant.Query(fmt.Sprintf("select tenant where user:%s is *", userId), &warrant.QueryParams{
+	queryResponse, err := warrant.NewClient(c.apiClient.Config).Query(fmt.Sprintf("select tenant where user:%s is *", userId), &warrant.QueryParams{
 		ListParams: listParams.ListParams,
 	})
 	if err != nil {
@@ -224,6 +287,371 @@ func ListTenantsForUser(userId string, listParams *warrant.ListTenantParams) (wa
 	return getClient().ListTenantsForUser(userId, listParams)
 }
 
+// AssignSubjectToTenant grants a subject, including a userset such as
+// warrant.Userset("group", "eng", "member"), relation on a tenant.
+func (c Client) AssignSubjectToTenant(tenantId string, relation string, subject warrant.Subject) (*warrant.Warrant, error) {
+	return warrant.NewClient(c.apiClient.Config).Create(&warrant.WarrantParams{
+		ObjectType: warrant.ObjectTypeTenant,
+		ObjectId:   tenantId,
+		Relation:   relation,
+		Subject:    subject,
+	})
+}
+
+func AssignSubjectToTenant(tenantId string, relation string, subject warrant.Subject) (*warrant.Warrant, error) {
+	return getClient().AssignSubjectToTenant(tenantId, relation, subject)
+}
+
+func (c Client) RemoveSubjectFromTenant(tenantId string, relation string, subject warrant.Subject) (string, error) {
+	return warrant.NewClient(c.apiClient.Config).Delete(&warrant.WarrantParams{
+		ObjectType: warrant.ObjectTypeTenant,
+		ObjectId:   tenantId,
+		Relation:   relation,
+		Subject:    subject,
+	})
+}
+
+func RemoveSubjectFromTenant(tenantId string, relation string, subject warrant.Subject) (string, error) {
+	return getClient().RemoveSubjectFromTenant(tenantId, relation, subject)
+}
+
+func (c Client) ListUsersetsForTenant(tenantId string, relation string, listParams *warrant.ListWarrantParams) (warrant.ListResponse[warrant.Subject], error) {
+	return warrant.NewClient(c.apiClient.Config).ListUsersets(warrant.ObjectTypeTenant, tenantId, relation, listParams)
+}
+
+func ListUsersetsForTenant(tenantId string, relation string, listParams *warrant.ListWarrantParams) (warrant.ListResponse[warrant.Subject], error) {
+	return getClient().ListUsersetsForTenant(tenantId, relation, listParams)
+}
+
+type CloneCategory string
+
+const (
+	// Feature warrants with the tenant as subject.
+	CloneFeatures CloneCategory = "features"
+	// Pricing tier warrants with the tenant as subject.
+	ClonePricingTiers CloneCategory = "pricingTiers"
+	// Every other warrant with the tenant (or one of its usersets) as
+	// subject, such as custom roles granted to the tenant's admins.
+	CloneSubjectWarrants CloneCategory = "subjectWarrants"
+	// Warrants on the tenant itself, such as default user memberships.
+	CloneMembers CloneCategory = "members"
+	// Roles assigned within the tenant (see role.AssignRoleToUserInTenant).
+	// Finding them lists every role membership.
+	CloneScopedRoles CloneCategory = "scopedRoles"
+	// The tenant's meta.
+	CloneMeta CloneCategory = "meta"
+)
+
+type CloneOptions struct {
+	// The categories to copy. Defaults to all of them.
+	Categories []CloneCategory
+	// Maps the id of the other object in each copied warrant, such as a
+	// custom role or a member user, to its id for the target tenant.
+	// Returning "" skips the warrant. Ids are kept when RemapId is nil.
+	RemapId func(objectType string, objectId string) string
+	// Report what would be copied without copying anything.
+	DryRun bool
+	// The most warrants created per batch request. Defaults to 100.
+	BatchSize int
+}
+
+type CloneReport struct {
+	SourceId string
+	TargetId string
+	// Whether the target tenant was created and the source's meta written
+	// to it. Both stay false with DryRun or if the write failed.
+	TenantCreated bool
+	MetaCopied    bool
+	// Whether the clone creates the target tenant and copies the source's
+	// meta, set with or without DryRun.
+	WouldCreateTenant bool
+	WouldCopyMeta     bool
+	// Every warrant created on the target.
+	Warrants []warrant.Warrant
+	// Every warrant still to be created on the target, because of DryRun
+	// or because a request failed.
+	Pending []warrant.Warrant
+	// The number of copied warrants the target already had.
+	ExistingWarrants int
+	CreatedWarrants  int
+	DryRun           bool
+	WarrantToken     string
+}
+
+// Clone copies a template tenant's pricing tiers, features, other subject
+// warrants, memberships, tenant-scoped role assignments and meta to a target
+// tenant, creating the target if it doesn't exist. Copied tenant-scoped
+// assignments are scoped to the target. Warrants the target already has are skipped, so a clone
+// that fails part way can be run again. If a request fails, the returned
+// report shows how far the clone got.
+func (c Client) Clone(sourceId string, targetId string, options *CloneOptions) (*CloneReport, error) {
+	if options == nil {
+		options = &CloneOptions{}
+	}
+	categories := make(map[CloneCategory]bool)
+	for _, category := range options.Categories {
+		categories[category] = true
+	}
+	if len(categories) == 0 {
+		for _, category := range []CloneCategory{CloneFeatures, ClonePricingTiers, CloneSubjectWarrants, CloneMembers, CloneScopedRoles, CloneMeta} {
+			categories[category] = true
+		}
+	}
+	remapId := options.RemapId
+	if remapId == nil {
+		remapId = func(objectType string, objectId string) string {
+			return objectId
+		}
+	}
+	batchSize := options.BatchSize
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+	report := &CloneReport{
+		SourceId: sourceId,
+		TargetId: targetId,
+		Warrants: make([]warrant.Warrant, 0),
+		Pending:  make([]warrant.Warrant, 0),
+		DryRun:   options.DryRun,
+	}
+
+	getParams := &warrant.TenantParams{}
+	getParams.SetWarrantToken("latest")
+	sourceTenant, err := c.Get(sourceId, getParams)
+	if err != nil {
+		return report, err
+	}
+	targetTenant, err := c.Get(targetId, getParams)
+	var warrantErr warrant.Error
+	if err != nil && !(errors.As(err, &warrantErr) && warrantErr.StatusCode == http.StatusNotFound) {
+		return report, err
+	}
+	report.WouldCreateTenant = targetTenant == nil
+	report.WouldCopyMeta = categories[CloneMeta] && len(sourceTenant.Meta) > 0
+
+	warrantClient := warrant.NewClient(c.apiClient.Config)
+	sourceWarrants, err := tenantWarrants(warrantClient, sourceId, categories[CloneScopedRoles])
+	if err != nil {
+		return report, err
+	}
+	existingWarrants := make(map[warrant.Warrant]bool)
+	if targetTenant != nil {
+		targetWarrants, err := tenantWarrants(warrantClient, targetId, categories[CloneScopedRoles])
+		if err != nil {
+			return report, err
+		}
+		for _, targetWarrant := range targetWarrants {
+			existingWarrants[targetWarrant] = true
+		}
+	}
+	for _, sourceWarrant := range sourceWarrants {
+		if !categories[cloneCategory(sourceWarrant, sourceId)] {
+			continue
+		}
+		clonedWarrant, ok := cloneWarrant(sourceWarrant, sourceId, targetId, remapId)
+		if !ok {
+			continue
+		}
+		if existingWarrants[clonedWarrant] {
+			report.ExistingWarrants++
+			continue
+		}
+		existingWarrants[clonedWarrant] = true
+		report.Pending = append(report.Pending, clonedWarrant)
+	}
+	if options.DryRun {
+		return report, nil
+	}
+
+	tenantParams := &warrant.TenantParams{
+		TenantId: targetId,
+	}
+	if report.WouldCopyMeta {
+		tenantParams.Meta = sourceTenant.Meta
+	}
+	if report.WouldCreateTenant {
+		createdTenant, err := c.Create(tenantParams)
+		if err != nil {
+			return report, err
+		}
+		report.TenantCreated = true
+		report.MetaCopied = report.WouldCopyMeta
+		report.WarrantToken = createdTenant.WarrantToken
+	} else if report.WouldCopyMeta {
+		updatedTenant, err := c.Update(targetId, tenantParams)
+		if err != nil {
+			return report, err
+		}
+		report.MetaCopied = true
+		report.WarrantToken = updatedTenant.WarrantToken
+	}
+
+	for len(report.Pending) > 0 {
+		end := batchSize
+		if end > len(report.Pending) {
+			end = len(report.Pending)
+		}
+		warrantsToCreate := make([]warrant.WarrantParams, 0, end)
+		for _, warrantToCreate := range report.Pending[:end] {
+			warrantsToCreate = append(warrantsToCreate, warrant.WarrantParams{
+				ObjectType: warrantToCreate.ObjectType,
+				ObjectId:   warrantToCreate.ObjectId,
+				Relation:   warrantToCreate.Relation,
+				Subject:    warrantToCreate.Subject,
+				Policy:     warrantToCreate.Policy,
+			})
+		}
+		createdWarrants, err := warrantClient.BatchCreate(warrantsToCreate)
+		if err != nil {
+			return report, err
+		}
+		report.Warrants = append(report.Warrants, report.Pending[:end]...)
+		report.Pending = report.Pending[end:]
+		report.CreatedWarrants += end
+		if len(createdWarrants) > 0 {
+			report.WarrantToken = createdWarrants[0].WarrantToken
+		}
+	}
+	return report, nil
+}
+
+func Clone(sourceId string, targetId string, options *CloneOptions) (*CloneReport, error) {
+	return getClient().Clone(sourceId, targetId, options)
+}
+
+// tenantWarrants lists the warrants with a tenant as subject, those on the
+// tenant itself and, with scopedRoles, the role memberships scoped to the
+// tenant. Tenant scope policies are normalized to TenantScopePolicy so that
+// they compare equal.
+func tenantWarrants(warrantClient warrant.WarrantClient, tenantId string, scopedRoles bool) ([]warrant.Warrant, error) {
+	subjectWarrants, err := warrantClient.ListAll(&warrant.ListWarrantParams{SubjectType: warrant.ObjectTypeTenant, SubjectId: tenantId})
+	if err != nil {
+		return nil, err
+	}
+	objectWarrants, err := warrantClient.ListAll(&warrant.ListWarrantParams{ObjectType: warrant.ObjectTypeTenant, ObjectId: tenantId})
+	if err != nil {
+		return nil, err
+	}
+	foundWarrants := append(subjectWarrants, objectWarrants...)
+	if scopedRoles {
+		roleWarrants, err := warrantClient.ListAll(&warrant.ListWarrantParams{ObjectType: warrant.ObjectTypeRole, Relation: "member"})
+		if err != nil {
+			return nil, err
+		}
+		for _, roleWarrant := range roleWarrants {
+			if warrant.IsTenantScopePolicy(roleWarrant.Policy, tenantId) {
+				foundWarrants = append(foundWarrants, roleWarrant)
+			}
+		}
+	}
+
+	seen := make(map[warrant.Warrant]bool)
+	tenantWarrants := make([]warrant.Warrant, 0, len(foundWarrants))
+	for _, tenantWarrant := range foundWarrants {
+		tenantWarrant.IsImplicit = false
+		tenantWarrant.WarrantToken = ""
+		if warrant.IsTenantScopePolicy(tenantWarrant.Policy, tenantId) {
+			tenantWarrant.Policy = warrant.TenantScopePolicy(tenantId)
+		}
+		if seen[tenantWarrant] {
+			continue
+		}
+		seen[tenantWarrant] = true
+		tenantWarrants = append(tenantWarrants, tenantWarrant)
+	}
+	return tenantWarrants, nil
+}
+
+func cloneCategory(tenantWarrant warrant.Warrant, tenantId string) CloneCategory {
+	if tenantWarrant.ObjectType == warrant.ObjectTypeTenant && tenantWarrant.ObjectId == tenantId {
+		return CloneMembers
+	}
+	if tenantWarrant.Subject.ObjectType != warrant.ObjectTypeTenant || tenantWarrant.Subject.ObjectId != tenantId {
+		return CloneScopedRoles
+	}
+	switch tenantWarrant.ObjectType {
+	case warrant.ObjectTypeFeature:
+		return CloneFeatures
+	case warrant.ObjectTypePricingTier:
+		return ClonePricingTiers
+	}
+	return CloneSubjectWarrants
+}
+
+// cloneWarrant moves every reference to the source tenant in a warrant to the
+// target tenant and remaps the other object, or reports false if the warrant
+// is skipped.
+func cloneWarrant(sourceWarrant warrant.Warrant, sourceId string, targetId string, remapId func(objectType string, objectId string) string) (warrant.Warrant, bool) {
+	clonedWarrant := sourceWarrant
+	objectIsTenant := sourceWarrant.ObjectType == warrant.ObjectTypeTenant && sourceWarrant.ObjectId == sourceId
+	subjectIsTenant := sourceWarrant.Subject.ObjectType == warrant.ObjectTypeTenant && sourceWarrant.Subject.ObjectId == sourceId
+	if objectIsTenant {
+		clonedWarrant.ObjectId = targetId
+	} else {
+		clonedWarrant.ObjectId = remapId(sourceWarrant.ObjectType, sourceWarrant.ObjectId)
+	}
+	if subjectIsTenant {
+		clonedWarrant.Subject.ObjectId = targetId
+	} else {
+		clonedWarrant.Subject.ObjectId = remapId(sourceWarrant.Subject.ObjectType, sourceWarrant.Subject.ObjectId)
+	}
+	if warrant.IsTenantScopePolicy(clonedWarrant.Policy, sourceId) {
+		clonedWarrant.Policy = warrant.TenantScopePolicy(targetId)
+	}
+	return clonedWarrant, clonedWarrant.ObjectId != "" && clonedWarrant.Subject.ObjectId != ""
+}
+
+// TypedClient decodes tenant meta into M on reads and validates and encodes
+// it on writes. The ObjectId of each result is the tenant's id. It wraps
+// object.TypeClient.
+type TypedClient[M any] struct {
+	client object.TypeClient[M]
+}
+
+func Typed[M any](c Client) TypedClient[M] {
+	return TypedClient[M]{
+		client: object.TypedFor[M](object.NewClient(c.apiClient.Config), warrant.ObjectTypeTenant),
+	}
+}
+
+func (c TypedClient[M]) Get(tenantId string, params *warrant.TenantParams) (*warrant.ObjectOf[M], error) {
+	if params == nil {
+		params = &warrant.TenantParams{}
+	}
+	return c.client.Get(tenantId, params.RequestOptions)
+}
+
+func GetAs[M any](tenantId string, params *warrant.TenantParams) (*warrant.ObjectOf[M], error) {
+	return Typed[M](getClient()).Get(tenantId, params)
+}
+
+func (c TypedClient[M]) List(listParams *warrant.ListTenantParams) (warrant.ListResponse[warrant.ObjectOf[M]], error) {
+	if listParams == nil {
+		listParams = &warrant.ListTenantParams{}
+	}
+	return c.client.List(listParams.ListParams)
+}
+
+func ListAs[M any](listParams *warrant.ListTenantParams) (warrant.ListResponse[warrant.ObjectOf[M]], error) {
+	return Typed[M](getClient()).List(listParams)
+}
+
+func (c TypedClient[M]) Create(typedObject *warrant.ObjectOf[M]) (*warrant.ObjectOf[M], error) {
+	return c.client.Create(typedObject)
+}
+
+func CreateAs[M any](typedObject *warrant.ObjectOf[M]) (*warrant.ObjectOf[M], error) {
+	return Typed[M](getClient()).Create(typedObject)
+}
+
+func (c TypedClient[M]) Update(typedObject *warrant.ObjectOf[M]) (*warrant.ObjectOf[M], error) {
+	return c.client.Update(typedObject)
+}
+
+func UpdateAs[M any](typedObject *warrant.ObjectOf[M]) (*warrant.ObjectOf[M], error) {
+	return Typed[M](getClient()).Update(typedObject)
+}
+
 func getClient() Client {
 	config := warrant.ClientConfig{
 		ApiKey:                  warrant.ApiKey,
@@ -0,0 +1,258 @@
+package tenant
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/warrant-dev/warrant-go/v6"
+)
+
+// tenantServer serves tenants and a fixed set of warrants, filtered by the
+// list query, and records the writes made. The warrant batch numbered
+// failBatch (from 1) is rejected.
+type tenantServer struct {
+	tenants   map[string]map[string]interface{}
+	warrants  []warrant.Warrant
+	failBatch int
+
+	mu             sync.Mutex
+	warrantQueries []string
+	createdBatches [][]warrant.WarrantParams
+	writtenTenants []warrant.ObjectParams
+}
+
+func (server *tenantServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	tenantId, isTenantPath := strings.CutPrefix(r.URL.Path, "/v2/objects/tenant/")
+	switch {
+	case r.Method == http.MethodGet && isTenantPath:
+		meta, ok := server.tenants[tenantId]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"Tenant not found"}`))
+			return
+		}
+		json.NewEncoder(w).Encode(warrant.Object{ObjectType: warrant.ObjectTypeTenant, ObjectId: tenantId, Meta: meta})
+	case (r.Method == http.MethodPost && r.URL.Path == "/v2/objects") || (r.Method == http.MethodPut && isTenantPath):
+		var params warrant.ObjectParams
+		json.NewDecoder(r.Body).Decode(&params)
+		if params.ObjectId == "" {
+			params.ObjectId = tenantId
+		}
+		server.writtenTenants = append(server.writtenTenants, params)
+		w.Header().Set("Warrant-Token", "tenant-written")
+		json.NewEncoder(w).Encode(warrant.Object{ObjectType: warrant.ObjectTypeTenant, ObjectId: params.ObjectId, Meta: params.Meta})
+	case r.Method == http.MethodGet && r.URL.Path == "/v2/warrants":
+		server.warrantQueries = append(server.warrantQueries, r.URL.RawQuery)
+		query := r.URL.Query()
+		results := make([]warrant.Warrant, 0)
+		for _, stored := range server.warrants {
+			if matches(query.Get("objectType"), stored.ObjectType) &&
+				matches(query.Get("objectId"), stored.ObjectId) &&
+				matches(query.Get("relation"), stored.Relation) &&
+				matches(query.Get("subjectType"), stored.Subject.ObjectType) &&
+				matches(query.Get("subjectId"), stored.Subject.ObjectId) {
+				results = append(results, stored)
+			}
+		}
+		json.NewEncoder(w).Encode(warrant.ListResponse[warrant.Warrant]{Results: results})
+	case r.Method == http.MethodPost && r.URL.Path == "/v2/warrants":
+		var batch []warrant.WarrantParams
+		json.NewDecoder(r.Body).Decode(&batch)
+		if len(server.createdBatches)+1 == server.failBatch {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"message":"Invalid warrant"}`))
+			return
+		}
+		server.createdBatches = append(server.createdBatches, batch)
+		w.Header().Set("Warrant-Token", fmt.Sprintf("batch-%d", len(server.createdBatches)))
+		created := make([]warrant.Warrant, 0, len(batch))
+		for _, params := range batch {
+			created = append(created, warrant.Warrant{ObjectType: params.ObjectType, ObjectId: params.ObjectId, Relation: params.Relation, Subject: params.Subject, Policy: params.Policy})
+		}
+		json.NewEncoder(w).Encode(created)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func matches(filter string, value string) bool {
+	return filter == "" || filter == value
+}
+
+func newTestClient(t *testing.T, server *tenantServer) Client {
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+	return NewClient(warrant.ClientConfig{ApiKey: "key", ApiEndpoint: httpServer.URL})
+}
+
+func newWarrant(objectType string, objectId string, subject warrant.Subject, policy string) warrant.Warrant {
+	return warrant.Warrant{
+		ObjectType: objectType,
+		ObjectId:   objectId,
+		Relation:   "member",
+		Subject:    subject,
+		Policy:     policy,
+	}
+}
+
+func tenantSubject(tenantId string) warrant.Subject {
+	return warrant.Subject{ObjectType: warrant.ObjectTypeTenant, ObjectId: tenantId}
+}
+
+func userSubject(userId string) warrant.Subject {
+	return warrant.Subject{ObjectType: warrant.ObjectTypeUser, ObjectId: userId}
+}
+
+func remapTemplate(objectType string, objectId string) string {
+	return strings.Replace(objectId, "template-", "acme-", 1)
+}
+
+var (
+	ssoFeature    = newWarrant(warrant.ObjectTypeFeature, "sso", tenantSubject("template"), "")
+	proTier       = newWarrant(warrant.ObjectTypePricingTier, "pro", tenantSubject("template"), "")
+	tenantAdmins  = newWarrant(warrant.ObjectTypeRole, "template-admin", warrant.Userset(warrant.ObjectTypeTenant, "template", "admin"), "")
+	defaultMember = newWarrant(warrant.ObjectTypeTenant, "template", userSubject("1"), "")
+	scopedAdmin   = newWarrant(warrant.ObjectTypeRole, "template-admin", userSubject("2"), `tenant=="template"`)
+	otherScoped   = newWarrant(warrant.ObjectTypeRole, "admin", userSubject("3"), warrant.TenantScopePolicy("other"))
+	globalRole    = newWarrant(warrant.ObjectTypeRole, "admin", userSubject("4"), "")
+)
+
+func templateServer() *tenantServer {
+	return &tenantServer{
+		tenants:  map[string]map[string]interface{}{"template": {"plan": "pro"}},
+		warrants: []warrant.Warrant{ssoFeature, proTier, tenantAdmins, defaultMember, scopedAdmin, otherScoped, globalRole},
+	}
+}
+
+func TestClone(t *testing.T) {
+	assert := assert.New(t)
+	server := templateServer()
+	client := newTestClient(t, server)
+
+	report, err := client.Clone("template", "acme", &CloneOptions{RemapId: remapTemplate, BatchSize: 2})
+	assert.NoError(err)
+	assert.True(report.TenantCreated)
+	assert.True(report.MetaCopied)
+	if assert.Len(server.writtenTenants, 1) {
+		assert.Equal("acme", server.writtenTenants[0].ObjectId)
+		assert.Equal(map[string]interface{}{"plan": "pro"}, server.writtenTenants[0].Meta)
+	}
+	assert.Equal([]warrant.Warrant{
+		newWarrant(warrant.ObjectTypeFeature, "sso", tenantSubject("acme"), ""),
+		newWarrant(warrant.ObjectTypePricingTier, "pro", tenantSubject("acme"), ""),
+		newWarrant(warrant.ObjectTypeRole, "acme-admin", warrant.Userset(warrant.ObjectTypeTenant, "acme", "admin"), ""),
+		newWarrant(warrant.ObjectTypeTenant, "acme", userSubject("1"), ""),
+		newWarrant(warrant.ObjectTypeRole, "acme-admin", userSubject("2"), warrant.TenantScopePolicy("acme")),
+	}, report.Warrants)
+	assert.Empty(report.Pending)
+	assert.Equal(5, report.CreatedWarrants)
+	assert.Equal("batch-3", report.WarrantToken)
+	assert.Len(server.createdBatches, 3)
+}
+
+func TestCloneSkipsExistingWarrants(t *testing.T) {
+	assert := assert.New(t)
+	server := templateServer()
+	server.tenants["acme"] = map[string]interface{}{}
+	server.warrants = append(server.warrants,
+		newWarrant(warrant.ObjectTypeFeature, "sso", tenantSubject("acme"), ""),
+		newWarrant(warrant.ObjectTypeRole, "acme-admin", userSubject("2"), `tenant == 'acme'`),
+	)
+	client := newTestClient(t, server)
+
+	report, err := client.Clone("template", "acme", &CloneOptions{RemapId: remapTemplate})
+	assert.NoError(err)
+	assert.False(report.TenantCreated)
+	assert.True(report.MetaCopied)
+	assert.Equal(2, report.ExistingWarrants)
+	assert.Equal(3, report.CreatedWarrants)
+	if assert.Len(server.writtenTenants, 1) {
+		assert.Equal("acme", server.writtenTenants[0].ObjectId)
+	}
+}
+
+func TestCloneDryRun(t *testing.T) {
+	assert := assert.New(t)
+	server := templateServer()
+	client := newTestClient(t, server)
+
+	report, err := client.Clone("template", "acme", &CloneOptions{DryRun: true})
+	assert.NoError(err)
+	assert.True(report.DryRun)
+	assert.True(report.WouldCreateTenant)
+	assert.True(report.WouldCopyMeta)
+	assert.False(report.TenantCreated)
+	assert.False(report.MetaCopied)
+	assert.Empty(report.Warrants)
+	assert.Len(report.Pending, 5)
+	assert.Empty(server.writtenTenants)
+	assert.Empty(server.createdBatches)
+}
+
+func TestCloneReportsPendingWarrants(t *testing.T) {
+	assert := assert.New(t)
+	server := templateServer()
+	server.failBatch = 2
+	client := newTestClient(t, server)
+
+	report, err := client.Clone("template", "acme", &CloneOptions{BatchSize: 2})
+	assert.ErrorContains(err, "Invalid warrant")
+	assert.True(report.TenantCreated)
+	assert.Len(report.Warrants, 2)
+	assert.Len(report.Pending, 3)
+	assert.Equal(2, report.CreatedWarrants)
+	assert.Equal("batch-1", report.WarrantToken)
+}
+
+func TestCloneCategories(t *testing.T) {
+	assert := assert.New(t)
+	server := templateServer()
+	client := newTestClient(t, server)
+
+	report, err := client.Clone("template", "acme", &CloneOptions{Categories: []CloneCategory{CloneFeatures}, DryRun: true})
+	assert.NoError(err)
+	assert.False(report.WouldCopyMeta)
+	assert.Equal([]warrant.Warrant{newWarrant(warrant.ObjectTypeFeature, "sso", tenantSubject("acme"), "")}, report.Pending)
+	// Role memberships are only listed for CloneScopedRoles.
+	for _, query := range server.warrantQueries {
+		assert.NotContains(query, "objectType=role")
+	}
+
+	report, err = client.Clone("template", "acme", &CloneOptions{Categories: []CloneCategory{CloneScopedRoles}, DryRun: true})
+	assert.NoError(err)
+	assert.Equal([]warrant.Warrant{newWarrant(warrant.ObjectTypeRole, "template-admin", userSubject("2"), warrant.TenantScopePolicy("acme"))}, report.Pending)
+}
+
+func TestCloneWarrant(t *testing.T) {
+	assert := assert.New(t)
+
+	clonedWarrant, ok := cloneWarrant(defaultMember, "template", "acme", remapTemplate)
+	assert.True(ok)
+	assert.Equal(newWarrant(warrant.ObjectTypeTenant, "acme", userSubject("1"), ""), clonedWarrant)
+
+	clonedWarrant, ok = cloneWarrant(tenantAdmins, "template", "acme", remapTemplate)
+	assert.True(ok)
+	assert.Equal(newWarrant(warrant.ObjectTypeRole, "acme-admin", warrant.Userset(warrant.ObjectTypeTenant, "acme", "admin"), ""), clonedWarrant)
+
+	clonedWarrant, ok = cloneWarrant(scopedAdmin, "template", "acme", remapTemplate)
+	assert.True(ok)
+	assert.Equal(warrant.TenantScopePolicy("acme"), clonedWarrant.Policy)
+
+	conditional := newWarrant(warrant.ObjectTypeFeature, "sso", tenantSubject("template"), `ip == "10.0.0.1"`)
+	clonedWarrant, ok = cloneWarrant(conditional, "template", "acme", remapTemplate)
+	assert.True(ok)
+	assert.Equal(conditional.Policy, clonedWarrant.Policy)
+
+	_, ok = cloneWarrant(tenantAdmins, "template", "acme", func(objectType string, objectId string) string {
+		return ""
+	})
+	assert.False(ok)
+}
@@ -0,0 +1,65 @@
+package warrant
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// TenantScopeContextKey is the policy context key that tenant-scoped role
+// assignments are checked against. A role assigned to a user within a tenant
+// is a role member warrant with the policy tenant == "<tenantId>", so it only
+// applies to checks made with that tenant in their context.
+const TenantScopeContextKey = "tenant"
+
+// TenantScopePolicy returns the policy of warrants that only apply within a
+// tenant.
+func TenantScopePolicy(tenantId string) string {
+	return fmt.Sprintf("%s == %s", TenantScopeContextKey, strconv.Quote(tenantId))
+}
+
+// IsTenantScopePolicy reports whether policy is TenantScopePolicy(tenantId),
+// allowing for the spacing and quoting the Warrant API may normalize it to.
+func IsTenantScopePolicy(policy string, tenantId string) bool {
+	key, value, ok := strings.Cut(policy, "==")
+	if !ok || strings.TrimSpace(key) != TenantScopeContextKey {
+		return false
+	}
+	value = strings.TrimSpace(value)
+	if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+		value = `"` + strings.ReplaceAll(value[1:len(value)-1], `"`, `\"`) + `"`
+	}
+	unquoted, err := strconv.Unquote(value)
+	return err == nil && unquoted == tenantId
+}
+
+// TenantScopeContext returns the context of checks made within a tenant.
+func TenantScopeContext(tenantId string) PolicyContext {
+	return PolicyContext{
+		TenantScopeContextKey: tenantId,
+	}
+}
+
+// TenantScopedObjectTypes returns the object types tenant-scoped roles rely
+// on: roles whose members are granted the role's permissions. Pass them to
+// objecttype.Bootstrap to create any that are missing.
+func TenantScopedObjectTypes() []ObjectTypeParams {
+	return []ObjectTypeParams{
+		{
+			Type: ObjectTypeRole,
+			Relations: map[string]interface{}{
+				"member": map[string]interface{}{},
+			},
+		},
+		{
+			Type: ObjectTypePermission,
+			Relations: map[string]interface{}{
+				"member": map[string]interface{}{
+					"inheritIf":    "member",
+					"ofType":       ObjectTypeRole,
+					"withRelation": "member",
+				},
+			},
+		},
+	}
+}
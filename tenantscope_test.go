@@ -0,0 +1,31 @@
+package warrant
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsTenantScopePolicy(t *testing.T) {
+	tests := []struct {
+		policy string
+		want   bool
+	}{
+		{TenantScopePolicy("acme"), true},
+		{`tenant=="acme"`, true},
+		{`  tenant  ==  "acme" `, true},
+		{`tenant == 'acme'`, true},
+		{"tenant == `acme`", true},
+		{`tenant == "other"`, false},
+		{`tenant == "acme" && ip == "10.0.0.1"`, false},
+		{`org == "acme"`, false},
+		{`tenant != "acme"`, false},
+		{`tenant == acme`, false},
+		{"", false},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.want, IsTenantScopePolicy(test.policy, "acme"), test.policy)
+	}
+	assert.True(t, IsTenantScopePolicy(TenantScopePolicy(`a"b`), `a"b`))
+	assert.True(t, IsTenantScopePolicy(`tenant == 'a"b'`, `a"b`))
+}
@@ -0,0 +1,393 @@
+package tx
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/warrant-dev/warrant-go/v6"
+	"github.com/warrant-dev/warrant-go/v6/object"
+)
+
+type Op string
+
+const (
+	OpCreateWarrant Op = "createWarrant"
+	OpDeleteWarrant Op = "deleteWarrant"
+	OpCreateObject  Op = "createObject"
+	OpUpdateObject  Op = "updateObject"
+	OpDeleteObject  Op = "deleteObject"
+)
+
+// StepStatus is the outcome of a step. StepUnknown marks a step whose request
+// failed in a way that may still have been applied, such as a timeout or a 5xx
+// response; it is not compensated.
+type StepStatus string
+
+const (
+	StepPending            StepStatus = "pending"
+	StepApplied            StepStatus = "applied"
+	StepFailed             StepStatus = "failed"
+	StepUnknown            StepStatus = "unknown"
+	StepCompensated        StepStatus = "compensated"
+	StepCompensationFailed StepStatus = "compensationFailed"
+)
+
+// Step is a single queued operation. For object updates and deletes, Previous
+// holds the object as it was before the step and is what compensation restores.
+// For object creates, Created holds the object the server created, including
+// any id it generated, and is what compensation deletes.
+type Step struct {
+	Op              Op
+	Warrant         warrant.WarrantParams
+	Object          warrant.ObjectParams
+	Previous        *warrant.Object
+	Created         *warrant.Object
+	Status          StepStatus
+	Err             error
+	CompensationErr error
+}
+
+type Report struct {
+	Steps        []Step
+	Committed    bool
+	WarrantToken string
+}
+
+// CommitError is returned when a step fails. The steps applied before it are
+// compensated, and Compensated reports whether every compensation succeeded.
+// MayHaveApplied is set when the failed request may still have been applied
+// (see warrant.MayHaveApplied). Its steps are marked StepUnknown and are not
+// compensated, so the transaction may be left partly applied even when
+// Compensated is set.
+type CommitError struct {
+	Step           int
+	Op             Op
+	Err            error
+	Compensated    bool
+	MayHaveApplied bool
+}
+
+func (err CommitError) Error() string {
+	if err.MayHaveApplied {
+		return fmt.Sprintf("Warrant error: transaction step %d (%s) failed and may have been applied; earlier steps were %s: %s", err.Step, err.Op, err.rollback(), err.Err)
+	}
+	if err.Compensated {
+		return fmt.Sprintf("Warrant error: transaction step %d (%s) failed and was rolled back: %s", err.Step, err.Op, err.Err)
+	}
+	return fmt.Sprintf("Warrant error: transaction step %d (%s) failed and could not be fully rolled back: %s", err.Step, err.Op, err.Err)
+}
+
+func (err CommitError) rollback() string {
+	if err.Compensated {
+		return "rolled back"
+	}
+	return "not fully rolled back"
+}
+
+func (err CommitError) Unwrap() error {
+	return err.Err
+}
+
+var ErrAlreadyCommitted = errors.New("Warrant error: transaction already committed")
+
+type Client struct {
+	apiClient *warrant.ApiClient
+}
+
+func NewClient(config warrant.ClientConfig) Client {
+	return Client{
+		apiClient: warrant.NewApiClient(config),
+	}
+}
+
+// Tx queues warrant and object operations and runs them in order on Commit.
+// Consecutive operations of the same kind are sent as one batch request. If
+// any request fails, the operations already applied are undone in reverse
+// order: created warrants and objects are deleted, deleted warrants and
+// objects are re-created and updated objects get their previous meta back.
+// Warrants removed by the server along with a deleted object are not
+// restored. A Tx is not safe for concurrent use.
+type Tx struct {
+	warrantClient warrant.WarrantClient
+	objectClient  object.Client
+	steps         []Step
+	committed     bool
+}
+
+func (c Client) Begin() *Tx {
+	return &Tx{
+		warrantClient: warrant.NewClient(c.apiClient.Config),
+		objectClient:  object.NewClient(c.apiClient.Config),
+	}
+}
+
+func Begin() *Tx {
+	return getClient().Begin()
+}
+
+func (t *Tx) CreateWarrant(params warrant.WarrantParams) *Tx {
+	return t.add(Step{Op: OpCreateWarrant, Warrant: params})
+}
+
+func (t *Tx) DeleteWarrant(params warrant.WarrantParams) *Tx {
+	return t.add(Step{Op: OpDeleteWarrant, Warrant: params})
+}
+
+func (t *Tx) CreateObject(params warrant.ObjectParams) *Tx {
+	return t.add(Step{Op: OpCreateObject, Object: params})
+}
+
+func (t *Tx) UpdateObject(params warrant.ObjectParams) *Tx {
+	return t.add(Step{Op: OpUpdateObject, Object: params})
+}
+
+func (t *Tx) DeleteObject(objectType string, objectId string) *Tx {
+	return t.add(Step{Op: OpDeleteObject, Object: warrant.ObjectParams{ObjectType: objectType, ObjectId: objectId}})
+}
+
+func (t *Tx) add(step Step) *Tx {
+	step.Status = StepPending
+	t.steps = append(t.steps, step)
+	return t
+}
+
+// Commit runs the queued operations. On failure the returned error is a
+// CommitError and the report shows what was applied and compensated. A failed
+// request that may still have been applied, such as a timeout or 5xx, leaves
+// its steps StepUnknown rather than compensating them.
+func (t *Tx) Commit() (Report, error) {
+	if t.committed {
+		return Report{Steps: t.steps}, ErrAlreadyCommitted
+	}
+	t.committed = true
+
+	report := Report{
+		Steps: t.steps,
+	}
+	groups := groupSteps(t.steps)
+	for g, group := range groups {
+		warrantToken, err := t.apply(group)
+		if err == nil {
+			if warrantToken != "" {
+				report.WarrantToken = warrantToken
+			}
+			continue
+		}
+
+		compensated := true
+		for i := g - 1; i >= 0; i-- {
+			warrantToken, ok := t.compensate(groups[i])
+			if warrantToken != "" {
+				report.WarrantToken = warrantToken
+			}
+			compensated = compensated && ok
+		}
+		failedStep := firstFailed(t.steps, group)
+		return report, CommitError{
+			Step:           failedStep,
+			Op:             t.steps[failedStep].Op,
+			Err:            err,
+			Compensated:    compensated,
+			MayHaveApplied: t.steps[failedStep].Status == StepUnknown,
+		}
+	}
+	report.Committed = true
+	return report, nil
+}
+
+type stepGroup struct {
+	op    Op
+	steps []int
+}
+
+// groupSteps groups runs of consecutive steps that can share a batch request.
+// Object updates have no batch endpoint and always run alone.
+func groupSteps(steps []Step) []stepGroup {
+	groups := make([]stepGroup, 0)
+	for i, step := range steps {
+		last := len(groups) - 1
+		if last >= 0 && groups[last].op == step.Op && step.Op != OpUpdateObject {
+			groups[last].steps = append(groups[last].steps, i)
+			continue
+		}
+		groups = append(groups, stepGroup{op: step.Op, steps: []int{i}})
+	}
+	return groups
+}
+
+func firstFailed(steps []Step, group stepGroup) int {
+	for _, i := range group.steps {
+		if steps[i].Status == StepFailed || steps[i].Status == StepUnknown {
+			return i
+		}
+	}
+	return group.steps[0]
+}
+
+func (t *Tx) apply(group stepGroup) (string, error) {
+	var warrantToken string
+	var err error
+	// Whether the group's write was sent, as opposed to failing on the read
+	// that captures the previous object.
+	written := true
+	switch group.op {
+	case OpCreateWarrant:
+		var createdWarrants []warrant.Warrant
+		createdWarrants, err = t.warrantClient.BatchCreate(t.warrantParams(group))
+		if err == nil && len(createdWarrants) > 0 {
+			warrantToken = createdWarrants[0].WarrantToken
+		}
+	case OpDeleteWarrant:
+		warrantToken, err = t.warrantClient.BatchDelete(t.warrantParams(group))
+	case OpCreateObject:
+		var createdObjects []warrant.Object
+		createdObjects, err = t.objectClient.BatchCreate(t.objectParams(group))
+		if err == nil && len(createdObjects) == len(group.steps) {
+			for j, i := range group.steps {
+				t.steps[i].Created = &createdObjects[j]
+			}
+			warrantToken = createdObjects[0].WarrantToken
+		}
+	case OpUpdateObject:
+		step := &t.steps[group.steps[0]]
+		step.Previous, err = t.objectClient.Get(step.Object.ObjectType, step.Object.ObjectId, nil)
+		written = err == nil
+		if err == nil {
+			var updatedObject *warrant.Object
+			updatedObject, err = t.objectClient.Update(step.Object.ObjectType, step.Object.ObjectId, &step.Object)
+			if err == nil {
+				warrantToken = updatedObject.WarrantToken
+			}
+		}
+	case OpDeleteObject:
+		err = t.capturePrevious(group)
+		written = err == nil
+		if err == nil {
+			warrantToken, err = t.objectClient.BatchDelete(t.objectParams(group))
+		}
+	}
+
+	status := StepApplied
+	if err != nil {
+		status = StepFailed
+		if written && warrant.MayHaveApplied(err) {
+			status = StepUnknown
+		}
+	}
+	for _, i := range group.steps {
+		t.steps[i].Status = status
+		t.steps[i].Err = err
+	}
+	return warrantToken, err
+}
+
+// compensate undoes an applied group and reports whether it succeeded.
+func (t *Tx) compensate(group stepGroup) (string, bool) {
+	var warrantToken string
+	var err error
+	switch group.op {
+	case OpCreateWarrant:
+		warrantToken, err = t.warrantClient.BatchDelete(t.warrantParams(group))
+	case OpDeleteWarrant:
+		var createdWarrants []warrant.Warrant
+		createdWarrants, err = t.warrantClient.BatchCreate(t.warrantParams(group))
+		if err == nil && len(createdWarrants) > 0 {
+			warrantToken = createdWarrants[0].WarrantToken
+		}
+	case OpCreateObject:
+		createdObjects := make([]warrant.ObjectParams, 0, len(group.steps))
+		for _, i := range group.steps {
+			created := t.steps[i].Created
+			if created == nil {
+				err = warrant.Error{Message: fmt.Sprintf("Created %s object for step %d is unknown", t.steps[i].Object.ObjectType, i)}
+				break
+			}
+			createdObjects = append(createdObjects, warrant.ObjectParams{
+				ObjectType: created.ObjectType,
+				ObjectId:   created.ObjectId,
+			})
+		}
+		if err == nil {
+			warrantToken, err = t.objectClient.BatchDelete(createdObjects)
+		}
+	case OpUpdateObject:
+		step := t.steps[group.steps[0]]
+		var restoredObject *warrant.Object
+		restoredObject, err = t.objectClient.Update(step.Previous.ObjectType, step.Previous.ObjectId, &warrant.ObjectParams{
+			Meta: step.Previous.Meta,
+		})
+		if err == nil {
+			warrantToken = restoredObject.WarrantToken
+		}
+	case OpDeleteObject:
+		previousObjects := make([]warrant.ObjectParams, 0, len(group.steps))
+		for _, i := range group.steps {
+			previous := t.steps[i].Previous
+			previousObjects = append(previousObjects, warrant.ObjectParams{
+				ObjectType: previous.ObjectType,
+				ObjectId:   previous.ObjectId,
+				Meta:       previous.Meta,
+			})
+		}
+		var restoredObjects []warrant.Object
+		restoredObjects, err = t.objectClient.BatchCreate(previousObjects)
+		if err == nil && len(restoredObjects) > 0 {
+			warrantToken = restoredObjects[0].WarrantToken
+		}
+	}
+
+	status := StepCompensated
+	if err != nil {
+		status = StepCompensationFailed
+	}
+	for _, i := range group.steps {
+		t.steps[i].Status = status
+		t.steps[i].CompensationErr = err
+	}
+	return warrantToken, err == nil
+}
+
+func (t *Tx) capturePrevious(group stepGroup) error {
+	for _, i := range group.steps {
+		step := &t.steps[i]
+		previous, err := t.objectClient.Get(step.Object.ObjectType, step.Object.ObjectId, nil)
+		if err != nil {
+			return err
+		}
+		step.Previous = previous
+	}
+	return nil
+}
+
+func (t *Tx) warrantParams(group stepGroup) []warrant.WarrantParams {
+	params := make([]warrant.WarrantParams, 0, len(group.steps))
+	for _, i := range group.steps {
+		params = append(params, t.steps[i].Warrant)
+	}
+	return params
+}
+
+func (t *Tx) objectParams(group stepGroup) []warrant.ObjectParams {
+	params := make([]warrant.ObjectParams, 0, len(group.steps))
+	for _, i := range group.steps {
+		params = append(params, t.steps[i].Object)
+	}
+	return params
+}
+
+func getClient() Client {
+	config := warrant.ClientConfig{
+		ApiKey:                  warrant.ApiKey,
+		ApiEndpoint:             warrant.ApiEndpoint,
+		AuthorizeEndpoint:       warrant.AuthorizeEndpoint,
+		SelfServiceDashEndpoint: warrant.SelfServiceDashEndpoint,
+		HttpClient:              warrant.HttpClient,
+	}
+
+	return Client{
+		&warrant.ApiClient{
+			HttpClient: warrant.HttpClient,
+			Config:     config,
+		},
+	}
+}
@@ -0,0 +1,238 @@
+package tx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/warrant-dev/warrant-go/v6"
+)
+
+// txServer answers the warrant and object endpoints a transaction uses and
+// records each request as "METHOD path". Requests listed in fail are answered
+// with that status code instead.
+type txServer struct {
+	mu       sync.Mutex
+	requests []string
+	fail     map[string]int
+}
+
+func (server *txServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	request := fmt.Sprintf("%s %s", r.Method, r.URL.Path)
+	server.mu.Lock()
+	server.requests = append(server.requests, request)
+	server.mu.Unlock()
+
+	if statusCode, ok := server.fail[request]; ok {
+		w.WriteHeader(statusCode)
+		w.Write([]byte(`{"message":"Request failed"}`))
+		return
+	}
+	w.Header().Set("Warrant-Token", fmt.Sprintf("token-%d", len(server.requests)))
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/v2/warrants":
+		var params []warrant.WarrantParams
+		json.NewDecoder(r.Body).Decode(&params)
+		created := make([]warrant.Warrant, 0, len(params))
+		for _, p := range params {
+			created = append(created, warrant.Warrant{ObjectType: p.ObjectType, ObjectId: p.ObjectId, Relation: p.Relation, Subject: p.Subject})
+		}
+		json.NewEncoder(w).Encode(created)
+	case r.Method == http.MethodPost && r.URL.Path == "/v2/objects":
+		var params []warrant.ObjectParams
+		json.NewDecoder(r.Body).Decode(&params)
+		created := make([]warrant.Object, 0, len(params))
+		for i, p := range params {
+			objectId := p.ObjectId
+			if objectId == "" {
+				objectId = fmt.Sprintf("generated-%d", i)
+			}
+			created = append(created, warrant.Object{ObjectType: p.ObjectType, ObjectId: objectId, Meta: p.Meta})
+		}
+		json.NewEncoder(w).Encode(created)
+	case r.Method == http.MethodGet || r.Method == http.MethodPut:
+		parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/v2/objects/"), "/")
+		json.NewEncoder(w).Encode(warrant.Object{ObjectType: parts[0], ObjectId: parts[1], Meta: map[string]interface{}{"name": "previous"}})
+	}
+}
+
+func (server *txServer) requestLog() []string {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	return append([]string(nil), server.requests...)
+}
+
+func newTestClient(t *testing.T, server *txServer) Client {
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+	return NewClient(warrant.ClientConfig{ApiKey: "key", ApiEndpoint: httpServer.URL})
+}
+
+func viewer(objectId string) warrant.WarrantParams {
+	return warrant.WarrantParams{
+		ObjectType: "document",
+		ObjectId:   objectId,
+		Relation:   "viewer",
+		Subject:    warrant.Subject{ObjectType: "user", ObjectId: "1"},
+	}
+}
+
+func TestGroupSteps(t *testing.T) {
+	steps := []Step{
+		{Op: OpCreateWarrant},
+		{Op: OpCreateWarrant},
+		{Op: OpCreateObject},
+		{Op: OpUpdateObject},
+		{Op: OpUpdateObject},
+		{Op: OpDeleteObject},
+		{Op: OpDeleteObject},
+		{Op: OpCreateWarrant},
+	}
+	assert.Equal(t, []stepGroup{
+		{op: OpCreateWarrant, steps: []int{0, 1}},
+		{op: OpCreateObject, steps: []int{2}},
+		{op: OpUpdateObject, steps: []int{3}},
+		{op: OpUpdateObject, steps: []int{4}},
+		{op: OpDeleteObject, steps: []int{5, 6}},
+		{op: OpCreateWarrant, steps: []int{7}},
+	}, groupSteps(steps))
+}
+
+func TestCommit(t *testing.T) {
+	assert := assert.New(t)
+	server := &txServer{}
+	client := newTestClient(t, server)
+
+	report, err := client.Begin().
+		CreateWarrant(viewer("a")).
+		CreateWarrant(viewer("b")).
+		DeleteObject("document", "c").
+		Commit()
+	assert.NoError(err)
+	assert.True(report.Committed)
+	assert.Equal([]string{
+		"POST /v2/warrants",
+		"GET /v2/objects/document/c",
+		"DELETE /v2/objects",
+	}, server.requestLog())
+	for _, step := range report.Steps {
+		assert.Equal(StepApplied, step.Status)
+	}
+	assert.Equal("previous", report.Steps[2].Previous.Meta["name"])
+	assert.Equal("token-3", report.WarrantToken)
+}
+
+func TestCommitCompensatesInReverseOrder(t *testing.T) {
+	assert := assert.New(t)
+	server := &txServer{fail: map[string]int{"PUT /v2/objects/document/b": http.StatusBadRequest}}
+	client := newTestClient(t, server)
+
+	report, err := client.Begin().
+		CreateWarrant(viewer("a")).
+		CreateObject(warrant.ObjectParams{ObjectType: "document"}).
+		UpdateObject(warrant.ObjectParams{ObjectType: "document", ObjectId: "b"}).
+		Commit()
+	var commitErr CommitError
+	if assert.ErrorAs(err, &commitErr) {
+		assert.Equal(2, commitErr.Step)
+		assert.Equal(OpUpdateObject, commitErr.Op)
+		assert.True(commitErr.Compensated)
+		assert.False(commitErr.MayHaveApplied)
+		assert.ErrorContains(err, "was rolled back")
+	}
+	assert.False(report.Committed)
+	assert.Equal([]string{
+		"POST /v2/warrants",
+		"POST /v2/objects",
+		"GET /v2/objects/document/b",
+		"PUT /v2/objects/document/b",
+		"DELETE /v2/objects",
+		"DELETE /v2/warrants",
+	}, server.requestLog())
+	assert.Equal(StepCompensated, report.Steps[0].Status)
+	assert.Equal(StepCompensated, report.Steps[1].Status)
+	assert.Equal("generated-0", report.Steps[1].Created.ObjectId)
+	assert.Equal(StepFailed, report.Steps[2].Status)
+	assert.Equal("token-6", report.WarrantToken)
+}
+
+func TestCommitReportsFailedCompensation(t *testing.T) {
+	assert := assert.New(t)
+	server := &txServer{fail: map[string]int{
+		"POST /v2/objects":    http.StatusBadRequest,
+		"DELETE /v2/warrants": http.StatusBadRequest,
+	}}
+	client := newTestClient(t, server)
+
+	report, err := client.Begin().
+		CreateWarrant(viewer("a")).
+		CreateObject(warrant.ObjectParams{ObjectType: "document", ObjectId: "b"}).
+		Commit()
+	var commitErr CommitError
+	if assert.ErrorAs(err, &commitErr) {
+		assert.Equal(1, commitErr.Step)
+		assert.False(commitErr.Compensated)
+		assert.ErrorContains(err, "could not be fully rolled back")
+	}
+	assert.Equal(StepCompensationFailed, report.Steps[0].Status)
+	assert.Error(report.Steps[0].CompensationErr)
+	assert.Equal(StepFailed, report.Steps[1].Status)
+}
+
+func TestCommitMarksStepsThatMayHaveAppliedAsUnknown(t *testing.T) {
+	assert := assert.New(t)
+	server := &txServer{fail: map[string]int{"DELETE /v2/objects": http.StatusServiceUnavailable}}
+	client := newTestClient(t, server)
+
+	report, err := client.Begin().
+		CreateWarrant(viewer("a")).
+		DeleteObject("document", "b").
+		DeleteObject("document", "c").
+		Commit()
+	var commitErr CommitError
+	if assert.ErrorAs(err, &commitErr) {
+		assert.Equal(1, commitErr.Step)
+		assert.True(commitErr.MayHaveApplied)
+		assert.True(commitErr.Compensated)
+		assert.ErrorContains(err, "may have been applied; earlier steps were rolled back")
+	}
+	assert.Equal(StepCompensated, report.Steps[0].Status)
+	assert.Equal(StepUnknown, report.Steps[1].Status)
+	assert.Equal(StepUnknown, report.Steps[2].Status)
+}
+
+func TestCommitFailedReadIsNotUnknown(t *testing.T) {
+	assert := assert.New(t)
+	server := &txServer{fail: map[string]int{"GET /v2/objects/document/b": http.StatusServiceUnavailable}}
+	client := newTestClient(t, server)
+
+	report, err := client.Begin().
+		UpdateObject(warrant.ObjectParams{ObjectType: "document", ObjectId: "b"}).
+		Commit()
+	var commitErr CommitError
+	if assert.ErrorAs(err, &commitErr) {
+		assert.False(commitErr.MayHaveApplied)
+	}
+	assert.Equal(StepFailed, report.Steps[0].Status)
+	assert.Equal([]string{"GET /v2/objects/document/b"}, server.requestLog())
+}
+
+func TestCommitTwice(t *testing.T) {
+	assert := assert.New(t)
+	server := &txServer{}
+	client := newTestClient(t, server)
+
+	tx := client.Begin().CreateWarrant(viewer("a"))
+	_, err := tx.Commit()
+	assert.NoError(err)
+	report, err := tx.Commit()
+	assert.True(errors.Is(err, ErrAlreadyCommitted))
+	assert.False(report.Committed)
+	assert.Len(server.requestLog(), 1)
+}
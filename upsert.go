@@ -0,0 +1,16 @@
+package warrant
+
+type UpsertAction string
+
+const (
+	UpsertCreated   UpsertAction = "created"
+	UpsertUpdated   UpsertAction = "updated"
+	UpsertUnchanged UpsertAction = "unchanged"
+)
+
+// UpsertResult holds the record after an upsert and whether the upsert
+// created it, updated its meta or found it already up to date.
+type UpsertResult[T any] struct {
+	Record T
+	Action UpsertAction
+}
@@ -3,8 +3,9 @@ package warrant
 const ObjectTypeUser = "user"
 
 type User struct {
-	UserId string                 `json:"userId"`
-	Meta   map[string]interface{} `json:"meta,omitempty"`
+	UserId       string                 `json:"userId"`
+	Meta         map[string]interface{} `json:"meta,omitempty"`
+	WarrantToken string                 `json:"warrantToken,omitempty"`
 }
 
 func (user User) GetObjectType() string {
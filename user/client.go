@@ -31,13 +31,14 @@ func (c Client) Create(params *warrant.UserParams) (*warrant.User, error) {
 	if params.Meta != nil {
 		objectParams.Meta = params.Meta
 	}
-	object, err := object.Create(&objectParams)
+	object, err := object.NewClient(c.apiClient.Config).Create(&objectParams)
 	if err != nil {
 		return nil, err
 	}
 	return &warrant.User{
-		UserId: object.ObjectId,
-		Meta:   object.Meta,
+		UserId:       object.ObjectId,
+		Meta:         object.Meta,
+		WarrantToken: object.WarrantToken,
 	}, nil
 }
 
@@ -56,7 +57,7 @@ func (c Client) BatchCreate(params []warrant.UserParams) ([]warrant.User, error)
 		})
 	}
 
-	createdObjects, err := object.BatchCreate(objectsToCreate)
+	createdObjects, err := object.NewClient(c.apiClient.Config).BatchCreate(objectsToCreate)
 	if err != nil {
 		return nil, err
 	}
@@ -64,8 +65,9 @@ func (c Client) BatchCreate(params []warrant.UserParams) ([]warrant.User, error)
 	users := make([]warrant.User, 0)
 	for _, createdObject := range createdObjects {
 		users = append(users, warrant.User{
-			UserId: createdObject.ObjectId,
-			Meta:   createdObject.Meta,
+			UserId:       createdObject.ObjectId,
+			Meta:         createdObject.Meta,
+			WarrantToken: createdObject.WarrantToken,
 		})
 	}
 
@@ -86,7 +88,7 @@ func (c Client) Get(userId string, params *warrant.UserParams) (*warrant.User, e
 		RequestOptions: params.RequestOptions,
 		Meta:           params.Meta,
 	}
-	object, err := object.Get(warrant.ObjectTypeUser, userId, &objectParams)
+	object, err := object.NewClient(c.apiClient.Config).Get(warrant.ObjectTypeUser, userId, &objectParams)
 	if err != nil {
 		return nil, err
 	}
@@ -110,13 +112,14 @@ func (c Client) Update(userId string, params *warrant.UserParams) (*warrant.User
 		RequestOptions: params.RequestOptions,
 		Meta:           params.Meta,
 	}
-	object, err := object.Update(warrant.ObjectTypeUser, userId, &objectParams)
+	object, err := object.NewClient(c.apiClient.Config).Update(warrant.ObjectTypeUser, userId, &objectParams)
 	if err != nil {
 		return nil, err
 	}
 	return &warrant.User{
-		UserId: object.ObjectId,
-		Meta:   object.Meta,
+		UserId:       object.ObjectId,
+		Meta:         object.Meta,
+		WarrantToken: object.WarrantToken,
 	}, nil
 }
 
@@ -124,8 +127,66 @@ func Update(userId string, params *warrant.UserParams) (*warrant.User, error) {
 	return getClient().Update(userId, params)
 }
 
+func (c Client) Upsert(params *warrant.UserParams) (*warrant.UpsertResult[warrant.User], error) {
+	if params == nil {
+		params = &warrant.UserParams{}
+	}
+	return object.UpsertAs(object.NewClient(c.apiClient.Config), &warrant.ObjectParams{
+		RequestOptions: params.RequestOptions,
+		ObjectType:     warrant.ObjectTypeUser,
+		ObjectId:       params.UserId,
+		Meta:           params.Meta,
+	}, userFromObject)
+}
+
+func Upsert(params *warrant.UserParams) (*warrant.UpsertResult[warrant.User], error) {
+	return getClient().Upsert(params)
+}
+
+func (c Client) BatchUpsert(params []warrant.UserParams) ([]warrant.UpsertResult[warrant.User], error) {
+	objectsToUpsert := make([]warrant.ObjectParams, 0)
+	for _, userParam := range params {
+		objectsToUpsert = append(objectsToUpsert, warrant.ObjectParams{
+			RequestOptions: userParam.RequestOptions,
+			ObjectType:     warrant.ObjectTypeUser,
+			ObjectId:       userParam.UserId,
+			Meta:           userParam.Meta,
+		})
+	}
+	return object.BatchUpsertAs(object.NewClient(c.apiClient.Config), objectsToUpsert, userFromObject)
+}
+
+func BatchUpsert(params []warrant.UserParams) ([]warrant.UpsertResult[warrant.User], error) {
+	return getClient().BatchUpsert(params)
+}
+
+func userFromObject(userObject warrant.Object) warrant.User {
+	return warrant.User{
+		UserId:       userObject.ObjectId,
+		Meta:         userObject.Meta,
+		WarrantToken: userObject.WarrantToken,
+	}
+}
+
+// PatchMeta applies an RFC 7396 JSON merge patch to the user's meta. See
+// object.PatchMeta.
+func (c Client) PatchMeta(userId string, patch map[string]interface{}, options *object.PatchMetaOptions) (*warrant.User, error) {
+	patchedObject, err := object.NewClient(c.apiClient.Config).PatchMeta(warrant.ObjectTypeUser, userId, patch, options)
+	if err != nil {
+		return nil, err
+	}
+	return &warrant.User{
+		UserId: patchedObject.ObjectId,
+		Meta:   patchedObject.Meta,
+	}, nil
+}
+
+func PatchMeta(userId string, patch map[string]interface{}, options *object.PatchMetaOptions) (*warrant.User, error) {
+	return getClient().PatchMeta(userId, patch, options)
+}
+
 func (c Client) Delete(userId string) (string, error) {
-	return object.Delete(warrant.ObjectTypeUser, userId)
+	return object.NewClient(c.apiClient.Config).Delete(warrant.ObjectTypeUser, userId)
 }
 
 func Delete(userId string) (string, error) {
@@ -143,7 +204,7 @@ func (c Client) BatchDelete(params []warrant.UserParams) (string, error) {
 		})
 	}
 
-	warrantToken, err := object.BatchDelete(objectsToDelete)
+	warrantToken, err := object.NewClient(c.apiClient.Config).BatchDelete(objectsToDelete)
 	if err != nil {
 		return "", err
 	}
@@ -161,7 +222,7 @@ func (c Client) ListUsers(listParams *warrant.ListUserParams) (warrant.ListRespo
 	}
 	var usersListResponse warrant.ListResponse[warrant.User]
 
-	objectsListResponse, err := object.ListObjects(&warrant.ListObjectParams{
+	objectsListResponse, err := object.NewClient(c.apiClient.Config).ListObjects(&warrant.ListObjectParams{
 		ListParams: listParams.ListParams,
 		ObjectType: warrant.ObjectTypeUser,
 	})
@@ -196,7 +257,7 @@ func (c Client) ListUsersForTenant(tenantId string, listParams *warrant.ListUser
 	}
 	var usersListResponse warrant.ListResponse[warrant.User]
 
-	queryResponse, err := warrant.Query(fmt.Sprintf("select * of type user for tenant:%s", tenantId), &warrant.QueryParams{
+	queryResponse, err := warrant.NewClient(c.apiClient.Config).Query(fmt.Sprintf("select * of type user for tenant:%s", tenantId), &warrant.QueryParams{
 		ListParams: listParams.ListParams,
 	})
 	if err != nil {
@@ -225,7 +286,7 @@ func ListUsersForTenant(tenantId string, listParams *warrant.ListUserParams) (wa
 }
 
 func (c Client) AssignUserToTenant(userId string, tenantId string, role string) (*warrant.Warrant, error) {
-	return warrant.Create(&warrant.WarrantParams{
+	return warrant.NewClient(c.apiClient.Config).Create(&warrant.WarrantParams{
 		ObjectType: warrant.ObjectTypeTenant,
 		ObjectId:   tenantId,
 		Relation:   role,
@@ -241,7 +302,7 @@ func AssignUserToTenant(userId string, tenantId string, role string) (*warrant.W
 }
 
 func (c Client) RemoveUserFromTenant(userId string, tenantId string, role string) (string, error) {
-	return warrant.Delete(&warrant.WarrantParams{
+	return warrant.NewClient(c.apiClient.Config).Delete(&warrant.WarrantParams{
 		ObjectType: warrant.ObjectTypeTenant,
 		ObjectId:   tenantId,
 		Relation:   role,
@@ -256,6 +317,170 @@ func RemoveUserFromTenant(userId string, tenantId string, role string) (string,
 	return getClient().RemoveUserFromTenant(userId, tenantId, role)
 }
 
+type DeprovisionOptions struct {
+	// Only revoke the user's access within this tenant: their warrants on the
+	// tenant and the roles assigned to them within it.
+	TenantId string
+	// Delete the user after revoking their warrants, as object.DeleteCascade
+	// does, so warrants on the user as an object are revoked too. Cannot be
+	// combined with TenantId.
+	DeleteUser bool
+	// Report what would be revoked without revoking anything.
+	DryRun bool
+	// The most warrants revoked per batch request. Defaults to 100.
+	BatchSize int
+}
+
+type DeprovisionReport struct {
+	UserId   string
+	TenantId string
+	// Every warrant revoked.
+	Warrants []warrant.Warrant
+	// Every warrant found but not revoked, because of DryRun or because a
+	// request failed.
+	Pending []warrant.Warrant
+	// The number of warrants found on each object type, such as role or
+	// tenant.
+	ObjectTypes     map[string]int
+	RevokedWarrants int
+	UserDeleted     bool
+	DryRun          bool
+	WarrantToken    string
+}
+
+// Deprovision revokes every warrant in which the user is the subject, such as
+// their roles, permissions, features, pricing tiers and tenant memberships, in
+// batches, and then optionally deletes the user. If a request fails, the
+// returned report shows how far deprovisioning got.
+func (c Client) Deprovision(userId string, options *DeprovisionOptions) (*DeprovisionReport, error) {
+	if options == nil {
+		options = &DeprovisionOptions{}
+	}
+	if options.TenantId != "" && options.DeleteUser {
+		return nil, warrant.Error{
+			Message: "Cannot delete a user while deprovisioning them from a single tenant",
+		}
+	}
+	report := &DeprovisionReport{
+		UserId:      userId,
+		TenantId:    options.TenantId,
+		Warrants:    make([]warrant.Warrant, 0),
+		Pending:     make([]warrant.Warrant, 0),
+		ObjectTypes: make(map[string]int),
+		DryRun:      options.DryRun,
+	}
+
+	if options.DeleteUser {
+		cascadeReport, err := object.NewClient(c.apiClient.Config).DeleteCascade(warrant.ObjectTypeUser, userId, &object.DeleteCascadeOptions{
+			DryRun:    options.DryRun,
+			BatchSize: options.BatchSize,
+		})
+		if cascadeReport != nil {
+			report.record(cascadeReport.Warrants, cascadeReport.DeletedWarrants)
+			report.UserDeleted = cascadeReport.ObjectDeleted
+			report.WarrantToken = cascadeReport.WarrantToken
+		}
+		return report, err
+	}
+
+	warrantClient := warrant.NewClient(c.apiClient.Config)
+	userWarrants, err := warrantClient.ListAll(&warrant.ListWarrantParams{SubjectType: warrant.ObjectTypeUser, SubjectId: userId})
+	if err != nil {
+		return report, err
+	}
+	found := make([]warrant.Warrant, 0, len(userWarrants))
+	for _, userWarrant := range userWarrants {
+		if options.TenantId != "" && !inTenant(userWarrant, options.TenantId) {
+			continue
+		}
+		found = append(found, userWarrant)
+	}
+	if options.DryRun {
+		report.record(found, 0)
+		return report, nil
+	}
+
+	revoked, warrantToken, err := warrantClient.DeleteAll(found, options.BatchSize)
+	report.record(found, revoked)
+	report.WarrantToken = warrantToken
+	return report, err
+}
+
+// record splits the warrants found into the first revoked ones, which were
+// deleted in order, and the pending rest.
+func (report *DeprovisionReport) record(found []warrant.Warrant, revoked int) {
+	for _, foundWarrant := range found {
+		report.ObjectTypes[foundWarrant.ObjectType]++
+	}
+	report.Warrants = append(report.Warrants, found[:revoked]...)
+	report.Pending = append(report.Pending, found[revoked:]...)
+	report.RevokedWarrants = revoked
+}
+
+func Deprovision(userId string, options *DeprovisionOptions) (*DeprovisionReport, error) {
+	return getClient().Deprovision(userId, options)
+}
+
+// inTenant reports whether a warrant is on a tenant or only applies within
+// it, as a tenant-scoped role assignment does.
+func inTenant(userWarrant warrant.Warrant, tenantId string) bool {
+	if userWarrant.ObjectType == warrant.ObjectTypeTenant {
+		return userWarrant.ObjectId == tenantId
+	}
+	return warrant.IsTenantScopePolicy(userWarrant.Policy, tenantId)
+}
+
+// TypedClient decodes user meta into M on reads and validates and encodes
+// it on writes. The ObjectId of each result is the user's id. It wraps
+// object.TypeClient.
+type TypedClient[M any] struct {
+	client object.TypeClient[M]
+}
+
+func Typed[M any](c Client) TypedClient[M] {
+	return TypedClient[M]{
+		client: object.TypedFor[M](object.NewClient(c.apiClient.Config), warrant.ObjectTypeUser),
+	}
+}
+
+func (c TypedClient[M]) Get(userId string, params *warrant.UserParams) (*warrant.ObjectOf[M], error) {
+	if params == nil {
+		params = &warrant.UserParams{}
+	}
+	return c.client.Get(userId, params.RequestOptions)
+}
+
+func GetAs[M any](userId string, params *warrant.UserParams) (*warrant.ObjectOf[M], error) {
+	return Typed[M](getClient()).Get(userId, params)
+}
+
+func (c TypedClient[M]) List(listParams *warrant.ListUserParams) (warrant.ListResponse[warrant.ObjectOf[M]], error) {
+	if listParams == nil {
+		listParams = &warrant.ListUserParams{}
+	}
+	return c.client.List(listParams.ListParams)
+}
+
+func ListAs[M any](listParams *warrant.ListUserParams) (warrant.ListResponse[warrant.ObjectOf[M]], error) {
+	return Typed[M](getClient()).List(listParams)
+}
+
+func (c TypedClient[M]) Create(typedObject *warrant.ObjectOf[M]) (*warrant.ObjectOf[M], error) {
+	return c.client.Create(typedObject)
+}
+
+func CreateAs[M any](typedObject *warrant.ObjectOf[M]) (*warrant.ObjectOf[M], error) {
+	return Typed[M](getClient()).Create(typedObject)
+}
+
+func (c TypedClient[M]) Update(typedObject *warrant.ObjectOf[M]) (*warrant.ObjectOf[M], error) {
+	return c.client.Update(typedObject)
+}
+
+func UpdateAs[M any](typedObject *warrant.ObjectOf[M]) (*warrant.ObjectOf[M], error) {
+	return Typed[M](getClient()).Update(typedObject)
+}
+
 func getClient() Client {
 	config := warrant.ClientConfig{
 		ApiKey:                  warrant.ApiKey,
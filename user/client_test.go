@@ -0,0 +1,178 @@
+package user
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/warrant-dev/warrant-go/v6"
+)
+
+// warrantServer lists a fixed set of warrants, filtered by the list query,
+// and records the batches of warrants and the objects deleted. The batch
+// numbered failBatch (from 1) is rejected.
+type warrantServer struct {
+	warrants  []warrant.Warrant
+	failBatch int
+
+	mu             sync.Mutex
+	deletedBatches [][]warrant.WarrantParams
+	deletedObjects []string
+}
+
+func (server *warrantServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/v2/warrants":
+		query := r.URL.Query()
+		results := make([]warrant.Warrant, 0)
+		for _, stored := range server.warrants {
+			if matches(query.Get("objectType"), stored.ObjectType) &&
+				matches(query.Get("objectId"), stored.ObjectId) &&
+				matches(query.Get("subjectType"), stored.Subject.ObjectType) &&
+				matches(query.Get("subjectId"), stored.Subject.ObjectId) {
+				results = append(results, stored)
+			}
+		}
+		json.NewEncoder(w).Encode(warrant.ListResponse[warrant.Warrant]{Results: results})
+	case r.Method == http.MethodDelete && r.URL.Path == "/v2/warrants":
+		var batch []warrant.WarrantParams
+		json.NewDecoder(r.Body).Decode(&batch)
+		if len(server.deletedBatches)+1 == server.failBatch {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"message":"Invalid warrant"}`))
+			return
+		}
+		server.deletedBatches = append(server.deletedBatches, batch)
+		w.Header().Set("Warrant-Token", fmt.Sprintf("batch-%d", len(server.deletedBatches)))
+	case r.Method == http.MethodDelete:
+		server.deletedObjects = append(server.deletedObjects, r.URL.Path)
+		w.Header().Set("Warrant-Token", "object-deleted")
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func matches(filter string, value string) bool {
+	return filter == "" || filter == value
+}
+
+func newTestClient(t *testing.T, server *warrantServer) Client {
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+	return NewClient(warrant.ClientConfig{ApiKey: "key", ApiEndpoint: httpServer.URL})
+}
+
+func userWarrant(objectType string, objectId string, policy string) warrant.Warrant {
+	return warrant.Warrant{
+		ObjectType: objectType,
+		ObjectId:   objectId,
+		Relation:   "member",
+		Subject:    warrant.Subject{ObjectType: warrant.ObjectTypeUser, ObjectId: "1"},
+		Policy:     policy,
+	}
+}
+
+var (
+	adminRole     = userWarrant(warrant.ObjectTypeRole, "admin", "")
+	acmeTenant    = userWarrant(warrant.ObjectTypeTenant, "acme", "")
+	otherTenant   = userWarrant(warrant.ObjectTypeTenant, "other", "")
+	acmeEditor    = userWarrant(warrant.ObjectTypeRole, "editor", `tenant=="acme"`)
+	otherEditor   = userWarrant(warrant.ObjectTypeRole, "editor", warrant.TenantScopePolicy("other"))
+	viewFeature   = userWarrant(warrant.ObjectTypeFeature, "view", "")
+	managedByUser = warrant.Warrant{
+		ObjectType: warrant.ObjectTypeUser,
+		ObjectId:   "1",
+		Relation:   "manager",
+		Subject:    warrant.Subject{ObjectType: warrant.ObjectTypeUser, ObjectId: "2"},
+	}
+)
+
+func TestDeprovision(t *testing.T) {
+	assert := assert.New(t)
+	server := &warrantServer{warrants: []warrant.Warrant{adminRole, acmeTenant, acmeEditor, viewFeature, managedByUser}}
+	client := newTestClient(t, server)
+
+	report, err := client.Deprovision("1", &DeprovisionOptions{BatchSize: 3})
+	assert.NoError(err)
+	assert.Equal([]warrant.Warrant{adminRole, acmeTenant, acmeEditor, viewFeature}, report.Warrants)
+	assert.Empty(report.Pending)
+	assert.Equal(4, report.RevokedWarrants)
+	assert.Equal(map[string]int{"role": 2, "tenant": 1, "feature": 1}, report.ObjectTypes)
+	assert.Equal("batch-2", report.WarrantToken)
+	assert.False(report.UserDeleted)
+	if assert.Len(server.deletedBatches, 2) {
+		assert.Len(server.deletedBatches[0], 3)
+		assert.Equal(acmeEditor.Policy, server.deletedBatches[0][2].Policy)
+	}
+	assert.Empty(server.deletedObjects)
+}
+
+func TestDeprovisionReportsPendingWarrants(t *testing.T) {
+	assert := assert.New(t)
+	server := &warrantServer{warrants: []warrant.Warrant{adminRole, acmeTenant, acmeEditor, viewFeature}, failBatch: 2}
+	client := newTestClient(t, server)
+
+	report, err := client.Deprovision("1", &DeprovisionOptions{BatchSize: 2})
+	assert.ErrorContains(err, "Invalid warrant")
+	assert.Equal([]warrant.Warrant{adminRole, acmeTenant}, report.Warrants)
+	assert.Equal([]warrant.Warrant{acmeEditor, viewFeature}, report.Pending)
+	assert.Equal(2, report.RevokedWarrants)
+	assert.Equal("batch-1", report.WarrantToken)
+}
+
+func TestDeprovisionDryRun(t *testing.T) {
+	assert := assert.New(t)
+	server := &warrantServer{warrants: []warrant.Warrant{adminRole, acmeTenant}}
+	client := newTestClient(t, server)
+
+	report, err := client.Deprovision("1", &DeprovisionOptions{DryRun: true})
+	assert.NoError(err)
+	assert.True(report.DryRun)
+	assert.Empty(report.Warrants)
+	assert.Equal([]warrant.Warrant{adminRole, acmeTenant}, report.Pending)
+	assert.Zero(report.RevokedWarrants)
+	assert.Empty(server.deletedBatches)
+}
+
+func TestDeprovisionFromTenant(t *testing.T) {
+	assert := assert.New(t)
+	server := &warrantServer{warrants: []warrant.Warrant{adminRole, acmeTenant, otherTenant, acmeEditor, otherEditor, viewFeature}}
+	client := newTestClient(t, server)
+
+	report, err := client.Deprovision("1", &DeprovisionOptions{TenantId: "acme"})
+	assert.NoError(err)
+	assert.Equal("acme", report.TenantId)
+	assert.Equal([]warrant.Warrant{acmeTenant, acmeEditor}, report.Warrants)
+	assert.Equal(map[string]int{"tenant": 1, "role": 1}, report.ObjectTypes)
+
+	_, err = client.Deprovision("1", &DeprovisionOptions{TenantId: "acme", DeleteUser: true})
+	assert.ErrorContains(err, "Cannot delete a user")
+}
+
+func TestDeprovisionAndDeleteUser(t *testing.T) {
+	assert := assert.New(t)
+	server := &warrantServer{warrants: []warrant.Warrant{adminRole, acmeTenant, managedByUser}}
+	client := newTestClient(t, server)
+
+	report, err := client.Deprovision("1", &DeprovisionOptions{DeleteUser: true, DryRun: true})
+	assert.NoError(err)
+	assert.False(report.UserDeleted)
+	assert.Equal([]warrant.Warrant{managedByUser, adminRole, acmeTenant}, report.Pending)
+	assert.Empty(server.deletedBatches)
+	assert.Empty(server.deletedObjects)
+
+	report, err = client.Deprovision("1", &DeprovisionOptions{DeleteUser: true})
+	assert.NoError(err)
+	assert.True(report.UserDeleted)
+	assert.Equal([]warrant.Warrant{managedByUser, adminRole, acmeTenant}, report.Warrants)
+	assert.Empty(report.Pending)
+	assert.Equal(3, report.RevokedWarrants)
+	assert.Equal("object-deleted", report.WarrantToken)
+	assert.Equal([]string{"/v2/objects/user/1"}, server.deletedObjects)
+}
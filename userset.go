@@ -0,0 +1,83 @@
+package warrant
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Userset returns a subject referring to everyone with relation on an object,
+// such as the members of a tenant (tenant:acme#member).
+func Userset(objectType string, objectId string, relation string) Subject {
+	return Subject{
+		ObjectType: objectType,
+		ObjectId:   objectId,
+		Relation:   relation,
+	}
+}
+
+// ParseSubject parses a subject written as type:id or, for a userset,
+// type:id#relation.
+func ParseSubject(subject string) (Subject, error) {
+	object, relation, _ := strings.Cut(subject, "#")
+	objectType, objectId, ok := strings.Cut(object, ":")
+	if !ok || objectType == "" || objectId == "" {
+		return Subject{}, Error{Message: fmt.Sprintf("Invalid subject %q, expected type:id or type:id#relation", subject)}
+	}
+	if strings.Contains(subject, "#") && relation == "" {
+		return Subject{}, Error{Message: fmt.Sprintf("Invalid subject %q, relation is empty", subject)}
+	}
+	return Subject{
+		ObjectType: objectType,
+		ObjectId:   objectId,
+		Relation:   relation,
+	}, nil
+}
+
+func (subject Subject) String() string {
+	if subject.Relation != "" {
+		return fmt.Sprintf("%s:%s#%s", subject.ObjectType, subject.ObjectId, subject.Relation)
+	}
+	return fmt.Sprintf("%s:%s", subject.ObjectType, subject.ObjectId)
+}
+
+func (subject Subject) IsUserset() bool {
+	return subject.Relation != ""
+}
+
+// ListUsersets lists the usersets granted relation on an object, for example
+// the tenant:acme#admin subject of a role's member warrants. Results are
+// filtered from each page of warrants, so a page may hold fewer than
+// listParams.Limit usersets.
+func (c WarrantClient) ListUsersets(objectType string, objectId string, relation string, listParams *ListWarrantParams) (ListResponse[Subject], error) {
+	if listParams == nil {
+		listParams = &ListWarrantParams{}
+	}
+	var usersetsListResponse ListResponse[Subject]
+	warrantsListParams := *listParams
+	warrantsListParams.ObjectType = objectType
+	warrantsListParams.ObjectId = objectId
+	warrantsListParams.Relation = relation
+	warrantsListResponse, err := c.ListWarrants(&warrantsListParams)
+	if err != nil {
+		return usersetsListResponse, err
+	}
+
+	usersets := make([]Subject, 0)
+	for _, warrant := range warrantsListResponse.Results {
+		if warrant.Subject.IsUserset() {
+			usersets = append(usersets, warrant.Subject)
+		}
+	}
+
+	usersetsListResponse = ListResponse[Subject]{
+		Results:    usersets,
+		PrevCursor: warrantsListResponse.PrevCursor,
+		NextCursor: warrantsListResponse.NextCursor,
+	}
+
+	return usersetsListResponse, nil
+}
+
+func ListUsersets(objectType string, objectId string, relation string, listParams *ListWarrantParams) (ListResponse[Subject], error) {
+	return getClient().ListUsersets(objectType, objectId, relation, listParams)
+}
@@ -35,11 +35,12 @@ func (subject Subject) GetRelation() string {
 }
 
 type WarrantParams struct {
-	ObjectType string  `json:"objectType"`
-	ObjectId   string  `json:"objectId"`
-	Relation   string  `json:"relation"`
-	Subject    Subject `json:"subject"`
-	Policy     string  `json:"policy,omitempty"`
+	RequestOptions `json:"-"`
+	ObjectType     string  `json:"objectType"`
+	ObjectId       string  `json:"objectId"`
+	Relation       string  `json:"relation"`
+	Subject        Subject `json:"subject"`
+	Policy         string  `json:"policy,omitempty"`
 }
 
 type ListWarrantParams struct {
@@ -53,9 +54,10 @@ type ListWarrantParams struct {
 }
 
 type Object struct {
-	ObjectType string                 `json:"objectType"`
-	ObjectId   string                 `json:"objectId"`
-	Meta       map[string]interface{} `json:"meta"`
+	ObjectType   string                 `json:"objectType"`
+	ObjectId     string                 `json:"objectId"`
+	Meta         map[string]interface{} `json:"meta"`
+	WarrantToken string                 `json:"warrantToken,omitempty"`
 }
 
 func (object Object) GetObjectType() string {
@@ -84,6 +86,13 @@ type WarrantObject interface {
 	GetObjectId() string
 }
 
+// SubjectWithRelation is implemented by subjects that refer to everyone with
+// a relation on an object, such as the members of a role.
+type SubjectWithRelation interface {
+	WarrantObject
+	GetRelation() string
+}
+
 type WarrantCheck struct {
 	Object   WarrantObject `json:"object"`
 	Relation string        `json:"relation"`
@@ -92,31 +101,19 @@ type WarrantCheck struct {
 }
 
 func (warrantCheck WarrantCheck) MarshalJSON() ([]byte, error) {
-	var m map[string]interface{}
-	subject, ok := warrantCheck.Subject.(*Subject)
-	if ok {
-		m = map[string]interface{}{
-			"objectType": warrantCheck.Object.GetObjectType(),
-			"objectId":   warrantCheck.Object.GetObjectId(),
-			"relation":   warrantCheck.Relation,
-			"subject": map[string]interface{}{
-				"objectType": subject.GetObjectType(),
-				"objectId":   subject.GetObjectId(),
-				"relation":   subject.GetRelation(),
-			},
-			"context": warrantCheck.Context,
-		}
-	} else {
-		m = map[string]interface{}{
-			"objectType": warrantCheck.Object.GetObjectType(),
-			"objectId":   warrantCheck.Object.GetObjectId(),
-			"relation":   warrantCheck.Relation,
-			"subject": map[string]interface{}{
-				"objectType": warrantCheck.Subject.GetObjectType(),
-				"objectId":   warrantCheck.Subject.GetObjectId(),
-			},
-			"context": warrantCheck.Context,
-		}
+	subject := map[string]interface{}{
+		"objectType": warrantCheck.Subject.GetObjectType(),
+		"objectId":   warrantCheck.Subject.GetObjectId(),
+	}
+	if subjectWithRelation, ok := warrantCheck.Subject.(SubjectWithRelation); ok && subjectWithRelation.GetRelation() != "" {
+		subject["relation"] = subjectWithRelation.GetRelation()
+	}
+	m := map[string]interface{}{
+		"objectType": warrantCheck.Object.GetObjectType(),
+		"objectId":   warrantCheck.Object.GetObjectId(),
+		"relation":   warrantCheck.Relation,
+		"subject":    subject,
+		"context":    warrantCheck.Context,
 	}
 
 	return json.Marshal(m)
@@ -126,42 +123,78 @@ type WarrantCheckParams struct {
 	RequestOptions
 	WarrantCheck WarrantCheck `json:"warrantCheck"`
 	Debug        bool         `json:"debug,omitempty"`
+	FailureMode  FailureMode  `json:"-"`
 }
 
 type WarrantCheckManyParams struct {
 	RequestOptions
-	Op       string         `json:"op"`
-	Warrants []WarrantCheck `json:"warrants"`
-	Debug    bool           `json:"debug,omitempty"`
+	Op          string         `json:"op"`
+	Warrants    []WarrantCheck `json:"warrants"`
+	Debug       bool           `json:"debug,omitempty"`
+	FailureMode FailureMode    `json:"-"`
 }
 
 type WarrantCheckResult struct {
-	Code   int64  `json:"code"`
-	Result string `json:"result"`
+	Code         int64  `json:"code"`
+	Result       string `json:"result"`
+	WarrantToken string `json:"warrantToken,omitempty"`
 }
 
 type PermissionCheckParams struct {
 	RequestOptions
 	PermissionId string        `json:"permissionId"`
 	UserId       string        `json:"userId"`
+	Subject      *Subject      `json:"subject,omitempty"`
 	Context      PolicyContext `json:"context,omitempty"`
 	Debug        bool          `json:"debug,omitempty"`
+	FailureMode  FailureMode   `json:"-"`
+}
+
+func (params *PermissionCheckParams) warrantCheckParams() *WarrantCheckParams {
+	return &WarrantCheckParams{
+		RequestOptions: params.RequestOptions,
+		WarrantCheck: WarrantCheck{
+			Object: Object{
+				ObjectType: ObjectTypePermission,
+				ObjectId:   params.PermissionId,
+			},
+			Relation: "member",
+			Subject:  checkSubject(params.Subject, params.UserId),
+			Context:  params.Context,
+		},
+		Debug:       params.Debug,
+		FailureMode: params.FailureMode,
+	}
+}
+
+// checkSubject returns subject if set, and otherwise the user.
+func checkSubject(subject *Subject, userId string) Subject {
+	if subject != nil {
+		return *subject
+	}
+	return Subject{
+		ObjectType: ObjectTypeUser,
+		ObjectId:   userId,
+	}
 }
 
 type RoleCheckParams struct {
 	RequestOptions
-	RoleId  string        `json:"roleId"`
-	UserId  string        `json:"userId"`
-	Context PolicyContext `json:"context,omitempty"`
-	Debug   bool          `json:"debug,omitempty"`
+	RoleId      string        `json:"roleId"`
+	UserId      string        `json:"userId"`
+	Subject     *Subject      `json:"subject,omitempty"`
+	Context     PolicyContext `json:"context,omitempty"`
+	Debug       bool          `json:"debug,omitempty"`
+	FailureMode FailureMode   `json:"-"`
 }
 
 type FeatureCheckParams struct {
 	RequestOptions
-	FeatureId string        `json:"featureId"`
-	Subject   Subject       `json:"subject"`
-	Context   PolicyContext `json:"context,omitempty"`
-	Debug     bool          `json:"debug,omitempty"`
+	FeatureId   string        `json:"featureId"`
+	Subject     Subject       `json:"subject"`
+	Context     PolicyContext `json:"context,omitempty"`
+	Debug       bool          `json:"debug,omitempty"`
+	FailureMode FailureMode   `json:"-"`
 }
 
 type AccessCheckRequest struct {